@@ -0,0 +1,73 @@
+// Command guide-server serves the guide library over HTTP as read-only
+// JSON; see the server package for the routes.
+//
+// Usage:
+//
+//	guide-server [-addr host:port] [-dir path] [-preview]
+//
+// Without -dir it serves the library embedded in the binary. With -dir it
+// loads the library from path, the directory holding the group directories,
+// once at startup.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/server"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "the `address` to listen on")
+	dir := flag.String("dir", "", "serve the library in `path` instead of the embedded one")
+	preview := flag.Bool("preview", false, "also serve guides under test under /preview")
+	flag.Parse()
+
+	lib, err := load(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var opts []server.Option
+	if *preview {
+		opts = append(opts, server.WithPreview())
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(lib, opts...),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("serving guides on http://%s", *addr)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		log.Print(err)
+	}
+}
+
+// load returns the library in dir, or the embedded one if dir is empty.
+func load(dir string) (*userguides.Library, error) {
+	if dir == "" {
+		return userguides.Guides()
+	}
+	return userguides.Load(os.DirFS(dir), userguides.WithRoot("."))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+)
+
+func exportLibrary(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("export", stderr)
+	format := fs.String("format", "json", "output `format`: json, markdown or html")
+	published := fs.Bool("published", false, "export only the published guides")
+	out := fs.String("o", "", "the `directory` to write markdown and html pages to")
+	opts := export.SiteOptions{Values: map[string]string{}}
+	fs.StringVar(&opts.BaseURL, "base-url", "", "the `URL` of the Spacelift app, prepended to in-app links in markdown and html")
+	fs.Func("var", "render the ${`name`} placeholders in markdown and html as name=value; repeatable", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("expected name=value, got %q", s)
+		}
+		opts.Values[name] = value
+		return nil
+	})
+	if code, stop := parseFlags(fs, args); stop {
+		return code
+	}
+	dir, err := pathArg(fs)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl export: %v\n", err)
+		return exitUsage
+	}
+	switch *format {
+	case "json":
+		if len(opts.Values) > 0 || opts.BaseURL != "" {
+			fmt.Fprintln(stderr, "guidectl export: -var and -base-url only apply to -format markdown and html")
+			return exitUsage
+		}
+	case "markdown", "html":
+		if *out == "" {
+			fmt.Fprintf(stderr, "guidectl export: -format %s writes one page per guide and needs -o\n", *format)
+			return exitUsage
+		}
+	default:
+		fmt.Fprintf(stderr, "guidectl export: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	lib, err := load(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl export: %v\n", err)
+		return exitProblems
+	}
+	if *published {
+		lib = lib.Filter(userguides.ReleaseStatePublished)
+	}
+
+	var pages []export.Page
+	switch *format {
+	case "json":
+		err = export.Write(stdout, lib)
+	case "markdown":
+		pages = export.MarkdownSite(lib, opts)
+	case "html":
+		pages, err = export.HTMLSite(lib, opts)
+	}
+	if err == nil && pages != nil {
+		err = writePages(stdout, *out, pages)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl export: %v\n", err)
+		return exitProblems
+	}
+	return exitOK
+}
+
+// writePages writes the pages of a site to dir and prints their paths.
+func writePages(stdout io.Writer, dir string, pages []export.Page) error {
+	for _, page := range pages {
+		path := filepath.Join(dir, page.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, page.Content, 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+)
+
+func TestExport(t *testing.T) {
+	dir := writeLibrary(t, strings.Replace(testGuideYAML, "ordering: 1\n", "ordering: 1\nreleaseState: testing\n", 1))
+
+	code, stdout, stderr := runCommand(t, "export", "--format", "json", dir)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	var doc export.Document
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout, err)
+	}
+	if doc.FormatVersion != export.FormatVersion || len(doc.Groups) != 1 || doc.Groups[0].Chapters[0].Guides[0].Slug != "first-guide" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+
+	// -published leaves out the guide under test, and with it the group.
+	_, stdout, _ = runCommand(t, "export", "-published", dir)
+	if want := `"groups": []`; !strings.Contains(stdout, want) {
+		t.Errorf("expected output to contain %s, got:\n%s", want, stdout)
+	}
+
+	if code, _, stderr := runCommand(t, "export", "-format", "xml", dir); code != exitUsage || !strings.Contains(stderr, `unknown format "xml"`) {
+		t.Errorf("expected an unknown format error, got %d: %s", code, stderr)
+	}
+}
+
+func TestExport_Site(t *testing.T) {
+	dir := writeLibrary(t, strings.Replace(testGuideYAML, `"Do this"`, `"Open [Stacks](/stacks)"`, 1))
+
+	for _, format := range []string{"markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "site")
+			ext := map[string]string{"markdown": ".md", "html": ".html"}[format]
+
+			code, stdout, stderr := runCommand(t, "export", "-format", format, "-o", out, "-base-url", "https://example.app.spacelift.io", dir)
+			if code != exitOK {
+				t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			want := filepath.Join(out, "index"+ext) + "\n" + filepath.Join(out, "guides", "first-guide"+ext) + "\n"
+			if stdout != want {
+				t.Errorf("expected the written pages\n%s\ngot\n%s", want, stdout)
+			}
+			data, err := os.ReadFile(filepath.Join(out, "guides", "first-guide"+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "https://example.app.spacelift.io/stacks") {
+				t.Errorf("expected in-app links to be rewritten, got:\n%s", data)
+			}
+		})
+	}
+
+	if code, _, stderr := runCommand(t, "export", "-format", "html", dir); code != exitUsage || !strings.Contains(stderr, "needs -o") {
+		t.Errorf("expected a usage error without -o, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCommand(t, "export", "-var", "stack_name", dir); code != exitUsage || !strings.Contains(stderr, "expected name=value") {
+		t.Errorf("expected a usage error for a malformed -var, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := runCommand(t, "export", "-var", "stack_name=prod", dir); code != exitUsage || !strings.Contains(stderr, "only apply to -format markdown and html") {
+		t.Errorf("expected a usage error for -var with -format json, got %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// yamlFile is a group, chapter or guide file being formatted.
+type yamlFile struct {
+	path     string
+	original []byte
+	doc      *yaml.Node
+	// newPath differs from path when compaction renumbers the ordering
+	// prefix of a guide file name.
+	newPath string
+}
+
+// root returns the top-level mapping of the file.
+func (f *yamlFile) root() *yaml.Node {
+	if f.doc.Kind == yaml.DocumentNode && len(f.doc.Content) > 0 {
+		return f.doc.Content[0]
+	}
+	return f.doc
+}
+
+// chapterFiles are the files of a chapter directory.
+type chapterFiles struct {
+	slug    string
+	chapter *yamlFile
+	guides  []*yamlFile
+}
+
+// groupFiles are the files of a group directory.
+type groupFiles struct {
+	group    *yamlFile
+	chapters []*chapterFiles
+}
+
+func format(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("fmt", stderr)
+	check := fs.Bool("check", false, "list the files that are not formatted instead of rewriting them")
+	compact := fs.Bool("compact", false, "renumber chapter and guide orderings 1..N within their parent")
+	if code, stop := parseFlags(fs, args); stop {
+		return code
+	}
+	dir, err := pathArg(fs)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl fmt: %v\n", err)
+		return exitUsage
+	}
+
+	orders, err := documentOrders()
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl fmt: %v\n", err)
+		return exitProblems
+	}
+
+	groups, err := readLibraryFiles(dir)
+	code := exitOK
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl fmt: %v\n", err)
+		code = exitProblems
+	}
+
+	var files []*yamlFile
+	for _, group := range groups {
+		if group.group != nil {
+			formatNode(group.group.root(), orders.group)
+			files = append(files, group.group)
+		}
+		for _, chapter := range group.chapters {
+			if chapter.chapter != nil {
+				formatNode(chapter.chapter.root(), orders.chapter)
+				files = append(files, chapter.chapter)
+			}
+			for _, guide := range chapter.guides {
+				formatNode(guide.root(), orders.guide)
+				renumberSteps(guide.root())
+				files = append(files, guide)
+			}
+			if *compact {
+				compactGuides(chapter.guides)
+			}
+		}
+		if *compact {
+			compactChapters(group.chapters)
+		}
+	}
+
+	for _, f := range files {
+		data, err := encode(f.doc)
+		if err != nil {
+			fmt.Fprintf(stderr, "guidectl fmt: %s: %v\n", f.path, err)
+			code = exitProblems
+			continue
+		}
+		if bytes.Equal(data, f.original) && f.newPath == f.path {
+			continue
+		}
+
+		if *check {
+			fmt.Fprintln(stdout, f.path)
+			code = exitProblems
+			continue
+		}
+		if err := rewrite(f, data); err != nil {
+			fmt.Fprintf(stderr, "guidectl fmt: %v\n", err)
+			code = exitProblems
+			continue
+		}
+		fmt.Fprintln(stdout, f.newPath)
+	}
+
+	return code
+}
+
+// rewrite writes data to the file, moving it to its new path if needed.
+func rewrite(f *yamlFile, data []byte) error {
+	if f.newPath != f.path {
+		if _, err := os.Stat(f.newPath); err == nil {
+			return fmt.Errorf("cannot rename %s: %s already exists", f.path, f.newPath)
+		}
+		if err := os.Rename(f.path, f.newPath); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(f.newPath, data, 0o644)
+}
+
+// readLibraryFiles parses every group, chapter and guide file under dir.
+// Files that cannot be parsed are left out and reported in the error.
+func readLibraryFiles(dir string) ([]*groupFiles, error) {
+	var errs []error
+	read := func(path string) *yamlFile {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			return nil
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", path, err))
+			return nil
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			errs = append(errs, fmt.Errorf("%s: not a YAML mapping", path))
+			return nil
+		}
+		return &yamlFile{path: path, original: data, doc: &doc, newPath: path}
+	}
+
+	groupDirs, err := subdirectories(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*groupFiles
+	for _, groupDir := range groupDirs {
+		group := &groupFiles{group: read(filepath.Join(groupDir, "group.yaml"))}
+
+		chapterDirs, err := subdirectories(groupDir)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, chapterDir := range chapterDirs {
+			chapter := &chapterFiles{
+				slug:    filepath.Base(chapterDir),
+				chapter: read(filepath.Join(chapterDir, "chapter.yaml")),
+			}
+
+			entries, err := os.ReadDir(chapterDir)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") || entry.Name() == "chapter.yaml" {
+					continue
+				}
+				if guide := read(filepath.Join(chapterDir, entry.Name())); guide != nil {
+					chapter.guides = append(chapter.guides, guide)
+				}
+			}
+			group.chapters = append(group.chapters, chapter)
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, errors.Join(errs...)
+}
+
+// subdirectories lists the directories in dir, skipping hidden ones as the
+// loader does.
+func subdirectories(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dirs = append(dirs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// keyOrder is the canonical order of the keys of a mapping, and of the
+// mappings nested in it, taken from the property order of a JSON Schema.
+type keyOrder struct {
+	keys   []string
+	fields map[string]*keyOrder
+}
+
+type documentKeyOrders struct {
+	group, chapter, guide *keyOrder
+}
+
+// documentOrders returns the canonical key orders of the document files,
+// which follow the library's JSON Schemas.
+func documentOrders() (documentKeyOrders, error) {
+	var orders documentKeyOrders
+	for _, s := range []struct {
+		schema func() ([]byte, error)
+		order  **keyOrder
+	}{
+		{userguides.GroupSchema, &orders.group},
+		{userguides.ChapterSchema, &orders.chapter},
+		{userguides.GuideSchema, &orders.guide},
+	} {
+		data, err := s.schema()
+		if err != nil {
+			return documentKeyOrders{}, err
+		}
+		// JSON is YAML, and a YAML node keeps the order of the properties.
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return documentKeyOrders{}, err
+		}
+		*s.order = schemaOrder(doc.Content[0])
+	}
+	return orders, nil
+}
+
+// schemaOrder returns the key order of the objects described by schema. The
+// order of an array is the order of its items.
+func schemaOrder(schema *yaml.Node) *keyOrder {
+	if items := mappingValue(schema, "items"); items != nil {
+		return schemaOrder(items)
+	}
+	props := mappingValue(schema, "properties")
+	if props == nil || props.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	order := &keyOrder{fields: make(map[string]*keyOrder)}
+	for i := 0; i+1 < len(props.Content); i += 2 {
+		key := props.Content[i].Value
+		order.keys = append(order.keys, key)
+		order.fields[key] = schemaOrder(props.Content[i+1])
+	}
+	return order
+}
+
+// mappingValue returns the value of key in the mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// formatNode sorts the keys of every mapping in node into their canonical
+// order, keeping unknown keys last in their original order, and writes
+// multi-line strings as literal block scalars. A comment above the first
+// key, such as a file header, stays at the top of the mapping.
+func formatNode(node *yaml.Node, order *keyOrder) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		type entry struct{ key, value *yaml.Node }
+		entries := make([]entry, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			entries = append(entries, entry{node.Content[i], node.Content[i+1]})
+		}
+
+		var known []string
+		if order != nil {
+			known = order.keys
+		}
+		rank := func(key string) int {
+			if i := slices.Index(known, key); i >= 0 {
+				return i
+			}
+			return len(known)
+		}
+		var head string
+		if len(entries) > 0 {
+			head, entries[0].key.HeadComment = entries[0].key.HeadComment, ""
+		}
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			return cmp.Compare(rank(a.key.Value), rank(b.key.Value))
+		})
+		if head != "" {
+			first := entries[0].key
+			first.HeadComment = strings.TrimSuffix(head+"\n"+first.HeadComment, "\n")
+		}
+
+		node.Content = node.Content[:0]
+		for _, e := range entries {
+			node.Content = append(node.Content, e.key, e.value)
+			var child *keyOrder
+			if order != nil {
+				child = order.fields[e.key.Value]
+			}
+			formatNode(e.value, child)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			formatNode(item, order)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" && strings.Contains(strings.TrimSuffix(node.Value, "\n"), "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+	}
+}
+
+// renumberSteps sets the order of each step of a guide to its position,
+// starting at 1.
+func renumberSteps(guide *yaml.Node) {
+	steps := mappingValue(guide, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, step := range steps.Content {
+		if order := mappingValue(step, "order"); order != nil {
+			setInt(order, i+1)
+		}
+	}
+}
+
+// orderingPrefix matches the ordering prefix of a guide file name, as in
+// 01-launchpad.yaml.
+var orderingPrefix = regexp.MustCompile(`^(\d+)-`)
+
+// compactGuides renumbers the orderings of the guides of a chapter 1..N,
+// keeping their order. A file name prefixed with the old ordering gets the
+// new one.
+func compactGuides(guides []*yamlFile) {
+	sorted := sortByOrdering(guides, func(f *yamlFile) string {
+		return scalarValue(mappingValue(f.root(), "slug"))
+	})
+	for i, guide := range sorted {
+		setInt(mappingValue(guide.root(), "ordering"), i+1)
+
+		name := filepath.Base(guide.path)
+		if m := orderingPrefix.FindStringSubmatch(name); m != nil {
+			name = fmt.Sprintf("%0*d-%s", len(m[1]), i+1, name[len(m[0]):])
+			guide.newPath = filepath.Join(filepath.Dir(guide.path), name)
+		}
+	}
+}
+
+// compactChapters renumbers the orderings of the chapters of a group 1..N,
+// keeping their order.
+func compactChapters(chapters []*chapterFiles) {
+	var files []*yamlFile
+	slugs := make(map[*yamlFile]string)
+	for _, chapter := range chapters {
+		if chapter.chapter != nil {
+			files = append(files, chapter.chapter)
+			slugs[chapter.chapter] = chapter.slug
+		}
+	}
+
+	sorted := sortByOrdering(files, func(f *yamlFile) string { return slugs[f] })
+	for i, chapter := range sorted {
+		setInt(mappingValue(chapter.root(), "ordering"), i+1)
+	}
+}
+
+// sortByOrdering returns the files with an integer ordering in the order the
+// loader sorts them: by ordering, then slug.
+func sortByOrdering(files []*yamlFile, slug func(*yamlFile) string) []*yamlFile {
+	type entry struct {
+		file     *yamlFile
+		ordering int
+	}
+	var entries []entry
+	for _, f := range files {
+		ordering, err := strconv.Atoi(scalarValue(mappingValue(f.root(), "ordering")))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{f, ordering})
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if a.ordering != b.ordering {
+			return a.ordering - b.ordering
+		}
+		return strings.Compare(slug(a.file), slug(b.file))
+	})
+
+	sorted := make([]*yamlFile, len(entries))
+	for i, e := range entries {
+		sorted[i] = e.file
+	}
+	return sorted
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+func setInt(node *yaml.Node, n int) {
+	node.Kind = yaml.ScalarNode
+	node.Tag = "!!int"
+	node.Style = 0
+	node.Value = strconv.Itoa(n)
+}
+
+// sectionKeys are the top-level keys preceded by a blank line.
+var sectionKeys = []string{"steps", "completion"}
+
+// encode renders doc in the canonical layout: two-space indentation, with a
+// blank line before each section and between steps.
+func encode(doc *yaml.Node) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(b.String(), "\n")
+	out := make([]string, 0, len(lines)+8)
+	section, firstItem := "", true
+	for _, line := range lines {
+		blank := false
+		if key, _, ok := strings.Cut(line, ":"); ok && line[0] != ' ' && line[0] != '#' {
+			section, firstItem = key, true
+			blank = slices.Contains(sectionKeys, key)
+		} else if section == "steps" && strings.HasPrefix(line, "  - ") {
+			blank = !firstItem
+			firstItem = false
+		}
+
+		if blank {
+			// Keep comments attached to the line they precede.
+			i := len(out)
+			for i > 0 && isComment(out[i-1]) {
+				i--
+			}
+			out = slices.Insert(out, i, "\n")
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "")), nil
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "#")
+}
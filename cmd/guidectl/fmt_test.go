@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFmt_EmbeddedLibraryIsFormatted(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "fmt", "-check", "../../guides")
+	if code != exitOK {
+		t.Errorf("expected the library to be formatted, run guidectl fmt; unformatted files:\n%s%s", stdout, stderr)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	messy := `# The first guide.
+
+ordering: 1
+steps:
+  - title: "Step"
+    order: 3
+    instruction: "Line one\nLine two\n"
+  # Moved up from the end.
+  - order: 7
+    instruction: "Do that"
+    title: "Step"
+completion:
+  recommendedGuideIds: []
+  successMessage: "Done"
+metadata:
+  title: "First Guide"
+  prerequisites: []
+  labels: ["test"]
+  description: "test"
+  difficulty: "easy"
+  minutesToComplete: 10 # roughly
+slug: first-guide
+`
+	want := `# The first guide.
+
+slug: first-guide
+ordering: 1
+metadata:
+  title: "First Guide"
+  description: "test"
+  labels: ["test"]
+  difficulty: "easy"
+  minutesToComplete: 10 # roughly
+  prerequisites: []
+
+steps:
+  - order: 1
+    title: "Step"
+    instruction: |
+      Line one
+      Line two
+
+  # Moved up from the end.
+  - order: 2
+    title: "Step"
+    instruction: "Do that"
+
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: []
+`
+	dir := writeLibrary(t, messy)
+	path := filepath.Join(dir, "basics/intro/01-first-guide.yaml")
+
+	code, stdout, stderr := runCommand(t, "fmt", "-check", dir)
+	if code != exitProblems || stdout != path+"\n" {
+		t.Fatalf("expected -check to list %s and fail, got %d: %s%s", path, code, stdout, stderr)
+	}
+	if data, _ := os.ReadFile(path); string(data) != messy {
+		t.Fatal("expected -check not to rewrite the file")
+	}
+
+	code, stdout, stderr = runCommand(t, "fmt", dir)
+	if code != exitOK || stdout != path+"\n" {
+		t.Fatalf("expected fmt to rewrite %s, got %d: %s%s", path, code, stdout, stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, data)
+	}
+
+	// The result is formatted and passes lint.
+	if code, stdout, _ := runCommand(t, "fmt", "-check", dir); code != exitOK {
+		t.Errorf("expected formatting to be idempotent, got:\n%s", stdout)
+	}
+	if code, stdout, _ := runCommand(t, "lint", dir); code != exitOK {
+		t.Errorf("expected the formatted library to pass lint, got:\n%s", stdout)
+	}
+}
+
+func TestFmt_HeaderComment(t *testing.T) {
+	// The comment is attached to ordering, which moves below slug.
+	messy := "# head comment\nordering: 1\nslug: first-guide\n" + strings.TrimPrefix(testGuideYAML, "slug: first-guide\nordering: 1\n")
+	dir := writeLibrary(t, messy)
+	path := filepath.Join(dir, "basics/intro/01-first-guide.yaml")
+
+	if code, _, stderr := runCommand(t, "fmt", dir); code != exitOK {
+		t.Fatalf("expected fmt to succeed, got %d: %s", code, stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# head comment\nslug: first-guide\nordering: 1\n"; !strings.HasPrefix(string(data), want) {
+		t.Errorf("expected the header comment to stay at the top, got:\n%s", data)
+	}
+}
+
+func TestFmt_Compact(t *testing.T) {
+	dir := writeLibrary(t, testGuideYAML)
+	files := map[string]string{
+		"basics/intro/chapter.yaml":       "name: \"Intro\"\ndescription: \"test\"\nordering: 3\n",
+		"basics/advanced/chapter.yaml":    "name: \"Advanced\"\ndescription: \"test\"\nordering: 7\n",
+		"basics/intro/04-next-guide.yaml": strings.NewReplacer("first-guide", "next-guide", "ordering: 1", "ordering: 4").Replace(testGuideYAML),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without -compact the orderings are left alone.
+	if code, stdout, _ := runCommand(t, "fmt", dir); code != exitOK || stdout != "" {
+		t.Fatalf("expected nothing to format, got %d: %s", code, stdout)
+	}
+
+	code, _, stderr := runCommand(t, "fmt", "-compact", dir)
+	if code != exitOK {
+		t.Fatalf("expected fmt -compact to succeed, got %d: %s", code, stderr)
+	}
+
+	_, stdout, _ := runCommand(t, "tree", dir)
+	want := "1 Basics (basics) BEGINNER\n" +
+		"  1 Intro (intro)\n" +
+		"    1 First Guide (first-guide): 2 steps, 10 min\n" +
+		"    2 First Guide (next-guide): 2 steps, 10 min\n" +
+		"  2 Advanced (advanced)\n"
+	if stdout != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, stdout)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "basics/intro/02-next-guide.yaml")); err != nil {
+		t.Errorf("expected the guide file to be renamed after its new ordering: %v", err)
+	}
+}
+
+func TestFmt_InvalidYAML(t *testing.T) {
+	dir := writeLibrary(t, "slug: [\n")
+
+	code, _, stderr := runCommand(t, "fmt", dir)
+	if code != exitProblems || !strings.Contains(stderr, "01-first-guide.yaml") {
+		t.Errorf("expected the unparsable file to be reported, got %d: %s", code, stderr)
+	}
+}
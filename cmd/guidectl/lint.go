@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// lintProblem is the JSON form of a userguides.Problem.
+type lintProblem struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// lintResult is the output of lint -format json.
+type lintResult struct {
+	Valid    bool          `json:"valid"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Problems []lintProblem `json:"problems"`
+}
+
+func lint(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("lint", stderr)
+	format := fs.String("format", "text", "output `format`: text or json")
+	if code, stop := parseFlags(fs, args); stop {
+		return code
+	}
+	dir, err := pathArg(fs)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl lint: %v\n", err)
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "guidectl lint: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	var problems []userguides.Problem
+	lib, err := load(dir)
+	if err != nil {
+		var report *userguides.ValidationReport
+		if !errors.As(err, &report) {
+			fmt.Fprintf(stderr, "guidectl lint: %v\n", err)
+			return exitProblems
+		}
+		problems = report.Problems
+	} else {
+		problems = lib.Warnings()
+	}
+
+	result := lintResult{Problems: []lintProblem{}}
+	for _, p := range problems {
+		// Report paths as the user spelt them, so editors can open them.
+		p.Path = filepath.Join(dir, p.Path)
+		if p.Severity == userguides.SeverityError {
+			result.Errors++
+		} else {
+			result.Warnings++
+		}
+		result.Problems = append(result.Problems, lintProblem{
+			Path:     p.Path,
+			Line:     p.Line,
+			Column:   p.Column,
+			Severity: string(p.Severity),
+			Code:     string(p.Code),
+			Message:  p.Message,
+		})
+		if *format == "text" {
+			fmt.Fprintf(stdout, "%s [%s]\n", p.Error(), p.Code)
+		}
+	}
+	result.Valid = result.Errors == 0
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fmt.Fprintf(stderr, "guidectl lint: %v\n", err)
+			return exitProblems
+		}
+	} else {
+		fmt.Fprintf(stdout, "%s: %s, %s\n", dir, plural(result.Errors, "error"), plural(result.Warnings, "warning"))
+	}
+
+	if !result.Valid {
+		return exitProblems
+	}
+	return exitOK
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
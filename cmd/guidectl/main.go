@@ -0,0 +1,127 @@
+// Command guidectl helps authors write guides: it validates and formats the
+// library, scaffolds new groups, chapters and guides, prints the hierarchy
+// and exports the library for other services and the docs site.
+//
+// Usage:
+//
+//	guidectl lint [-format text|json] [path]
+//	guidectl fmt [-check] [-compact] [path]
+//	guidectl new [-dir path] group <name>
+//	guidectl new [-dir path] chapter <group> <name>
+//	guidectl new [-dir path] guide <group>/<chapter> <title>
+//	guidectl tree [path]
+//	guidectl export [-format json|markdown|html] [-published] [-o dir] [-base-url url] [-var name=value] [path]
+//
+// path is the directory holding the group directories and defaults to
+// guides.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0
+	exitProblems = 1
+	exitUsage    = 2
+)
+
+const usage = `usage: guidectl <command> [arguments]
+
+commands:
+  lint [-format text|json] [path]   validate the library
+  fmt [-check] [-compact] [path]    rewrite the YAML files in the canonical layout
+  new [-dir path] group <name>      scaffold a group
+  new [-dir path] chapter <group> <name>
+                                    scaffold a chapter
+  new [-dir path] guide <group>/<chapter> <title>
+                                    scaffold a guide
+  tree [path]                       print the group, chapter and guide hierarchy
+  export [-format json|markdown|html] [-published] [-o dir] [-base-url url] [-var name=value] [path]
+                                    write the library as a versioned JSON document,
+                                    or as markdown or html pages to dir
+
+path defaults to ` + userguides.DefaultRoot + `.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// command runs a subcommand with its arguments and returns the exit code.
+type command func(args []string, stdout, stderr io.Writer) int
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	commands := map[string]command{
+		"lint":   lint,
+		"fmt":    format,
+		"new":    scaffold,
+		"tree":   tree,
+		"export": exportLibrary,
+	}
+
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "guidectl: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+	return cmd(args[1:], stdout, stderr)
+}
+
+// newFlagSet returns a flag set for the named subcommand that reports
+// errors to stderr instead of exiting.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("guidectl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses args into fs and returns the exit code to stop with, if
+// any.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, true
+		}
+		return exitUsage, true
+	}
+	return 0, false
+}
+
+// pathArg returns the optional path argument of a subcommand.
+func pathArg(fs *flag.FlagSet) (string, error) {
+	switch fs.NArg() {
+	case 0:
+		return userguides.DefaultRoot, nil
+	case 1:
+		return fs.Arg(0), nil
+	default:
+		return "", fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args()[1:], " "))
+	}
+}
+
+// load loads the library rooted at dir, the directory holding the group
+// directories.
+func load(dir string) (*userguides.Library, error) {
+	return userguides.Load(os.DirFS(dir), userguides.WithRoot("."))
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGuideYAML = `slug: first-guide
+ordering: 1
+metadata:
+  title: "First Guide"
+  description: "test"
+  labels: ["test"]
+  difficulty: "easy"
+  minutesToComplete: 10
+  prerequisites: []
+
+steps:
+  - order: 1
+    title: "Step"
+    instruction: "Do this"
+
+  - order: 2
+    title: "Step"
+    instruction: "Do that"
+
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: []
+`
+
+// writeLibrary writes a library with one group, chapter and guide to a
+// temporary directory and returns its path.
+func writeLibrary(t *testing.T, guide string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"basics/group.yaml":                "name: \"Basics\"\ndescription: \"test\"\nskillLevel: BEGINNER\nordering: 1\n",
+		"basics/intro/chapter.yaml":        "name: \"Intro\"\ndescription: \"test\"\nordering: 1\n",
+		"basics/intro/01-first-guide.yaml": guide,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runCommand(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestLint(t *testing.T) {
+	dir := writeLibrary(t, testGuideYAML)
+
+	code, stdout, stderr := runCommand(t, "lint", dir)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s%s", exitOK, code, stdout, stderr)
+	}
+	if !strings.HasSuffix(stdout, ": 0 errors, 0 warnings\n") {
+		t.Errorf("expected a clean summary, got: %s", stdout)
+	}
+}
+
+func TestLint_Problems(t *testing.T) {
+	guide := strings.Replace(testGuideYAML, "    instruction: \"Do that\"\n", "    instruction: \"Do that\"\n    validaton: \"x\"\n", 1)
+	dir := writeLibrary(t, guide)
+
+	code, stdout, _ := runCommand(t, "lint", dir)
+	if code != exitProblems {
+		t.Errorf("expected exit code %d, got %d", exitProblems, code)
+	}
+	want := filepath.Join(dir, "basics/intro/01-first-guide.yaml") + `:19:5: steps[1]: unknown field "validaton", did you mean "validation"? [field-unknown]`
+	if !strings.Contains(stdout, want+"\n") {
+		t.Errorf("expected output to contain\n%s\ngot:\n%s", want, stdout)
+	}
+	if !strings.HasSuffix(stdout, ": 1 error, 0 warnings\n") {
+		t.Errorf("expected a summary with one error, got: %s", stdout)
+	}
+}
+
+func TestLint_JSON(t *testing.T) {
+	guide := strings.Replace(testGuideYAML, "minutesToComplete: 10", "minutesToComplete: 0", 1)
+	dir := writeLibrary(t, guide)
+
+	code, stdout, _ := runCommand(t, "lint", "-format", "json", dir)
+	if code != exitProblems {
+		t.Errorf("expected exit code %d, got %d", exitProblems, code)
+	}
+
+	var result lintResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", stdout, err)
+	}
+	if result.Valid || result.Errors != 1 || len(result.Problems) != 1 {
+		t.Fatalf("expected a single error, got: %+v", result)
+	}
+	p := result.Problems[0]
+	if p.Code != "guide-minutes-invalid" || p.Line != 8 || p.Severity != "error" || p.Path != filepath.Join(dir, "basics/intro/01-first-guide.yaml") {
+		t.Errorf("unexpected problem: %+v", p)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := writeLibrary(t, testGuideYAML)
+
+	steps := []struct {
+		args []string
+		path string
+	}{
+		{[]string{"group", "Cost Control"}, "cost-control/group.yaml"},
+		{[]string{"chapter", "cost-control", "Budgets & Alerts"}, "cost-control/budgets-alerts/chapter.yaml"},
+		{[]string{"guide", "cost-control/budgets-alerts", "Set a Budget"}, "cost-control/budgets-alerts/01-set-a-budget.yaml"},
+		{[]string{"guide", "basics/intro", "Second Guide"}, "basics/intro/02-second-guide.yaml"},
+		{[]string{"-slug", "another-intro", "chapter", "basics", "Intro"}, "basics/another-intro/chapter.yaml"},
+	}
+	for _, step := range steps {
+		args := append([]string{"new", "-dir", dir}, step.args...)
+		code, stdout, stderr := runCommand(t, args...)
+		if code != exitOK {
+			t.Fatalf("%v: expected exit code %d, got %d: %s", step.args, exitOK, code, stderr)
+		}
+		if want := "created " + filepath.Join(dir, step.path) + "\n"; stdout != want {
+			t.Errorf("%v: expected %q, got %q", step.args, want, stdout)
+		}
+	}
+
+	// Every scaffold must pass lint and be formatted, with the next free
+	// ordering.
+	if code, stdout, _ := runCommand(t, "lint", dir); code != exitOK {
+		t.Fatalf("expected the scaffolds to pass lint, got:\n%s", stdout)
+	}
+	if code, stdout, _ := runCommand(t, "fmt", "-check", dir); code != exitOK {
+		t.Errorf("expected the scaffolds to be formatted, got:\n%s", stdout)
+	}
+	_, stdout, _ := runCommand(t, "tree", dir)
+	for _, want := range []string{
+		"2 Cost Control (cost-control) BEGINNER\n",
+		"  2 Intro (another-intro)\n",
+		"    2 Second Guide (second-guide): 1 step, 5 min [testing]\n",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected tree to contain %q, got:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	dir := writeLibrary(t, testGuideYAML)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		err  string
+	}{
+		{"missing name", []string{"group"}, exitUsage, "usage"},
+		{"unknown kind", []string{"stack", "x"}, exitUsage, "usage"},
+		{"existing group", []string{"group", "Basics"}, exitProblems, "group basics already exists"},
+		{"unknown group", []string{"chapter", "missing", "Intro"}, exitProblems, "group missing not found"},
+		{"existing guide", []string{"guide", "basics/intro", "First Guide"}, exitProblems, "guide first-guide already exists"},
+		{"malformed chapter", []string{"guide", "basics", "Title"}, exitProblems, "<group>/<chapter>"},
+		{"no slug", []string{"group", "!!!"}, exitUsage, "cannot derive a slug"},
+		{"invalid slug", []string{"-slug", "Bad_Slug", "group", "Advanced"}, exitUsage, `invalid slug "Bad_Slug"`},
+		{"invalid skill level", []string{"-skill-level", "EXPERT", "group", "Advanced"}, exitUsage, `invalid skill level "EXPERT"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"new", "-dir", dir}, tt.args...)
+			code, _, stderr := runCommand(t, args...)
+			if code != tt.code || !strings.Contains(stderr, tt.err) {
+				t.Errorf("expected exit code %d and an error containing %q, got %d: %s", tt.code, tt.err, code, stderr)
+			}
+		})
+	}
+}
+
+func TestTree(t *testing.T) {
+	dir := writeLibrary(t, testGuideYAML)
+
+	code, stdout, stderr := runCommand(t, "tree", dir)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+
+	want := "1 Basics (basics) BEGINNER\n" +
+		"  1 Intro (intro)\n" +
+		"    1 First Guide (first-guide): 2 steps, 10 min\n"
+	if stdout != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, stdout)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	if code, _, stderr := runCommand(t); code != exitUsage || !strings.Contains(stderr, "usage:") {
+		t.Errorf("expected usage with exit code %d, got %d: %s", exitUsage, code, stderr)
+	}
+	if code, _, stderr := runCommand(t, "bogus"); code != exitUsage || !strings.Contains(stderr, `unknown command "bogus"`) {
+		t.Errorf("expected an unknown command error, got %d: %s", code, stderr)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Cost Control":         "cost-control",
+		"  Budgets & Alerts! ": "budgets-alerts",
+		"Step 2: Deploy":       "step-2-deploy",
+		"Café":                 "caf",
+		"!!!":                  "",
+	}
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
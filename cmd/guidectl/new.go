@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// The scaffolds pass lint as written, so an author can scaffold several
+// files before filling any of them in. New guides are testing until the
+// author publishes them.
+var scaffolds = template.Must(template.New("").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(`
+{{- define "group" -}}
+name: {{quote .Name}}
+description: "TODO: describe what the group covers"
+skillLevel: {{.SkillLevel}}
+ordering: {{.Ordering}}
+{{end}}
+
+{{- define "chapter" -}}
+name: {{quote .Name}}
+description: "TODO: describe what the chapter covers"
+ordering: {{.Ordering}}
+{{end}}
+
+{{- define "guide" -}}
+slug: {{.Slug}}
+ordering: {{.Ordering}}
+releaseState: testing
+metadata:
+  title: {{quote .Name}}
+  description: "TODO: summarize what the guide covers"
+  labels: ["todo"]
+  difficulty: "easy"
+  minutesToComplete: 5
+  prerequisites: []
+
+steps:
+  - order: 1
+    title: "TODO: name the first step"
+    instruction: "TODO: tell the user what to do"
+
+completion:
+  successMessage: "TODO: congratulate the user"
+  recommendedGuideIds: []
+{{end}}
+`))
+
+var (
+	// slugPattern matches the slugs the guide schema accepts.
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	// skillLevels are the skill levels a group may have.
+	skillLevels = []string{"BEGINNER", "ENABLER", "COMMANDER", "GUARDIAN"}
+)
+
+// scaffoldData fills a scaffold template.
+type scaffoldData struct {
+	Name       string
+	Slug       string
+	SkillLevel string
+	Ordering   int
+}
+
+func scaffold(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("new", stderr)
+	dir := fs.String("dir", userguides.DefaultRoot, "the `path` holding the group directories")
+	slug := fs.String("slug", "", "the `slug` to use instead of the one derived from the name")
+	skillLevel := fs.String("skill-level", "BEGINNER", "the skill `level` of a new group")
+	if code, stop := parseFlags(fs, args); stop {
+		return code
+	}
+
+	kind, rest := fs.Arg(0), fs.Args()
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	want := map[string]int{"group": 1, "chapter": 2, "guide": 2}
+	if n, ok := want[kind]; !ok || len(rest) != n {
+		fmt.Fprintf(stderr, "guidectl new: usage:\n  guidectl new group <name>\n  guidectl new chapter <group> <name>\n  guidectl new guide <group>/<chapter> <title>\n")
+		return exitUsage
+	}
+	if *slug != "" && !slugPattern.MatchString(*slug) {
+		fmt.Fprintf(stderr, "guidectl new: invalid slug %q (must be lower-case letters and digits separated by single hyphens)\n", *slug)
+		return exitUsage
+	}
+	if !slices.Contains(skillLevels, *skillLevel) {
+		fmt.Fprintf(stderr, "guidectl new: invalid skill level %q (must be %s)\n", *skillLevel, strings.Join(skillLevels, ", "))
+		return exitUsage
+	}
+
+	lib, err := load(*dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl new: the library has problems, run guidectl lint to list them\n")
+		return exitProblems
+	}
+
+	name := rest[len(rest)-1]
+	data := scaffoldData{Name: name, Slug: *slug, SkillLevel: *skillLevel}
+	if data.Slug == "" {
+		data.Slug = slugify(name)
+	}
+	if data.Slug == "" {
+		fmt.Fprintf(stderr, "guidectl new: cannot derive a slug from %q, use -slug\n", name)
+		return exitUsage
+	}
+
+	var path string
+	switch kind {
+	case "group":
+		path, err = newGroup(lib, *dir, &data)
+	case "chapter":
+		path, err = newChapter(lib, *dir, rest[0], &data)
+	case "guide":
+		path, err = newGuide(lib, *dir, rest[0], &data)
+	}
+	if err == nil {
+		err = writeScaffold(path, kind, data)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl new: %v\n", err)
+		return exitProblems
+	}
+
+	fmt.Fprintf(stdout, "created %s\n", path)
+	return exitOK
+}
+
+func newGroup(lib *userguides.Library, dir string, data *scaffoldData) (string, error) {
+	if _, ok := lib.GroupBySlug(data.Slug); ok {
+		return "", fmt.Errorf("group %s already exists", data.Slug)
+	}
+
+	for _, group := range lib.Groups {
+		data.Ordering = max(data.Ordering, group.Ordering)
+	}
+	data.Ordering++
+
+	return filepath.Join(dir, data.Slug, "group.yaml"), nil
+}
+
+func newChapter(lib *userguides.Library, dir, groupSlug string, data *scaffoldData) (string, error) {
+	group, ok := lib.GroupBySlug(groupSlug)
+	if !ok {
+		return "", fmt.Errorf("group %s not found", groupSlug)
+	}
+	if _, ok := lib.ChapterByPath(groupSlug, data.Slug); ok {
+		return "", fmt.Errorf("chapter %s/%s already exists", groupSlug, data.Slug)
+	}
+
+	for _, chapter := range group.Chapters {
+		data.Ordering = max(data.Ordering, chapter.Ordering)
+	}
+	data.Ordering++
+
+	return filepath.Join(dir, groupSlug, data.Slug, "chapter.yaml"), nil
+}
+
+func newGuide(lib *userguides.Library, dir, chapterPath string, data *scaffoldData) (string, error) {
+	groupSlug, chapterSlug, ok := strings.Cut(chapterPath, "/")
+	if !ok {
+		return "", fmt.Errorf("chapter must be given as <group>/<chapter>, got %q", chapterPath)
+	}
+	chapter, ok := lib.ChapterByPath(groupSlug, chapterSlug)
+	if !ok {
+		return "", fmt.Errorf("chapter %s not found", chapterPath)
+	}
+	// Guide slugs are unique across the library, not just the chapter.
+	if _, ok := lib.GuideBySlug(data.Slug); ok {
+		return "", fmt.Errorf("guide %s already exists", data.Slug)
+	}
+
+	for _, guide := range chapter.Guides {
+		data.Ordering = max(data.Ordering, guide.Ordering)
+	}
+	data.Ordering++
+
+	file := fmt.Sprintf("%02d-%s.yaml", data.Ordering, data.Slug)
+	return filepath.Join(dir, groupSlug, chapterSlug, file), nil
+}
+
+// writeScaffold renders the named scaffold to path, which must not exist.
+func writeScaffold(path, name string, data scaffoldData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	err = scaffolds.ExecuteTemplate(f, name, data)
+	return errors.Join(err, f.Close())
+}
+
+// slugify derives a slug from a display name: lower-case letters and digits,
+// with every other run of characters replaced by a single hyphen.
+func slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
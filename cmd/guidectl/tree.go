@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+func tree(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("tree", stderr)
+	if code, stop := parseFlags(fs, args); stop {
+		return code
+	}
+	dir, err := pathArg(fs)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl tree: %v\n", err)
+		return exitUsage
+	}
+
+	lib, err := load(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "guidectl tree: %v\n", err)
+		return exitProblems
+	}
+
+	printTree(stdout, lib)
+	return exitOK
+}
+
+// printTree writes one line per group, chapter and guide, indented by
+// depth and prefixed with the ordering.
+func printTree(w io.Writer, lib *userguides.Library) {
+	for _, group := range lib.Groups {
+		fmt.Fprintf(w, "%d %s (%s) %s\n", group.Ordering, group.Name, group.Slug, group.SkillLevel)
+		for _, chapter := range group.Chapters {
+			fmt.Fprintf(w, "  %d %s (%s)\n", chapter.Ordering, chapter.Name, chapter.Slug)
+			for _, guide := range chapter.Guides {
+				fmt.Fprintf(w, "    %d %s (%s): %s, %d min", guide.Ordering, guide.Metadata.Title, guide.Slug, plural(len(guide.Steps), "step"), guide.Metadata.MinutesToComplete)
+				if guide.ReleaseState == userguides.ReleaseStateTesting {
+					fmt.Fprint(w, " [testing]")
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+}
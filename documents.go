@@ -0,0 +1,73 @@
+package userguides
+
+import (
+	"reflect"
+	"slices"
+)
+
+// groupDocument is the content of a group.yaml file.
+type groupDocument struct {
+	Name        string `yaml:"name" description:"Display name of the group"`
+	Description string `yaml:"description" description:"Short summary of what the group covers"`
+	SkillLevel  string `yaml:"skillLevel" description:"Target skill level for this group" jsonschema:"enum=BEGINNER|ENABLER|COMMANDER|GUARDIAN"`
+	Ordering    int    `yaml:"ordering" description:"Display order of the group" jsonschema:"minimum=1"`
+}
+
+// chapterDocument is the content of a chapter.yaml file.
+type chapterDocument struct {
+	Name        string          `yaml:"name" description:"Display name of the chapter"`
+	Description string          `yaml:"description" description:"Short summary of what the chapter covers"`
+	Ordering    int             `yaml:"ordering" description:"Display order of the chapter within its group" jsonschema:"minimum=1"`
+	Variables   []GuideVariable `yaml:"variables,omitempty" description:"Template variables available to all guides in this chapter"`
+}
+
+// guideDocument is the content of a guide file.
+type guideDocument struct {
+	Slug                   string          `yaml:"slug" description:"Unique identifier for the guide, used in URLs and cross-references" jsonschema:"pattern=^[a-z0-9]+(-[a-z0-9]+)*$"`
+	Ordering               int             `yaml:"ordering" description:"Display order of the guide within its chapter" jsonschema:"minimum=1"`
+	ReleaseState           ReleaseState    `yaml:"releaseState,omitempty" description:"Release state of the guide" jsonschema:"enum=testing|published"`
+	PrerequisiteGuideSlugs []string        `yaml:"prerequisiteGuideSlugs,omitempty" description:"Slugs of guides that should be completed before this one" jsonschema:"pattern=^[a-z0-9]+(-[a-z0-9]+)*$"`
+	Metadata               GuideMetadata   `yaml:"metadata" description:"Descriptive metadata about the guide"`
+	Steps                  []GuideStep     `yaml:"steps" description:"Ordered list of steps the user follows to complete the guide" jsonschema:"minItems=1"`
+	Completion             GuideCompletion `yaml:"completion" description:"Information displayed when the guide is completed"`
+}
+
+// The $ids of the document schemas, which are also their file names.
+const (
+	groupSchema   = "group_schema.json"
+	chapterSchema = "chapter_schema.json"
+	guideSchema   = "guide_schema.json"
+)
+
+// The document schemas are generated from the document types. The loader
+// enforces the generated schemas, so the published files are only copies;
+// a test fails if they are stale.
+var (
+	groupDocumentSchema = generateSchema(reflect.TypeFor[groupDocument](), groupSchema,
+		"Spacelift Guide Group", "Schema for group.yaml files that define a top-level group of chapters")
+	chapterDocumentSchema = generateSchema(reflect.TypeFor[chapterDocument](), chapterSchema,
+		"Spacelift Guide Chapter", "Schema for chapter.yaml files that define a chapter containing guides")
+	guideDocumentSchema = generateSchema(reflect.TypeFor[guideDocument](), guideSchema,
+		"Spacelift User Guide", "Schema for Spacelift user guide YAML files")
+)
+
+// GroupSchema returns the JSON Schema of group.yaml files, as published in
+// schema/group_schema.json.
+func GroupSchema() ([]byte, error) {
+	data, err := groupDocumentSchema()
+	return slices.Clone(data), err
+}
+
+// ChapterSchema returns the JSON Schema of chapter.yaml files, as published
+// in schema/chapter_schema.json.
+func ChapterSchema() ([]byte, error) {
+	data, err := chapterDocumentSchema()
+	return slices.Clone(data), err
+}
+
+// GuideSchema returns the JSON Schema of guide files, as published in
+// schema/guide_schema.json.
+func GuideSchema() ([]byte, error) {
+	data, err := guideDocumentSchema()
+	return slices.Clone(data), err
+}
@@ -0,0 +1,230 @@
+// Package export converts a library into the versioned JSON document that
+// is shipped to the frontend and other services, and into Markdown or HTML
+// pages for the docs site.
+//
+// The document has explicit field names that do not depend on the YAML
+// layout of the guides, and its content is sorted, so exporting the same
+// library always produces the same bytes. Every field is present: strings
+// that are not set are "", lists that are not set are [].
+package export
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// FormatVersion is the version of the document format. It is incremented
+// whenever a change could break consumers, such as removing or renaming a
+// field; adding a field does not change it.
+const FormatVersion = 1
+
+// Document is the exported library.
+type Document struct {
+	FormatVersion int     `json:"formatVersion"`
+	Groups        []Group `json:"groups"`
+}
+
+// Group is an exported userguides.Group.
+type Group struct {
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	SkillLevel  string    `json:"skillLevel"`
+	Ordering    int       `json:"ordering"`
+	Chapters    []Chapter `json:"chapters"`
+}
+
+// Chapter is an exported userguides.Chapter.
+type Chapter struct {
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Ordering    int        `json:"ordering"`
+	Variables   []Variable `json:"variables"`
+	Guides      []Guide    `json:"guides"`
+}
+
+// Variable is an exported userguides.GuideVariable.
+type Variable struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ResourceType string `json:"resourceType"`
+}
+
+// Guide is an exported userguides.Guide. ReleaseState is always set, to
+// published for guides without one.
+type Guide struct {
+	Slug                   string     `json:"slug"`
+	Ordering               int        `json:"ordering"`
+	ReleaseState           string     `json:"releaseState"`
+	PrerequisiteGuideSlugs []string   `json:"prerequisiteGuideSlugs"`
+	Metadata               Metadata   `json:"metadata"`
+	Steps                  []Step     `json:"steps"`
+	Completion             Completion `json:"completion"`
+}
+
+// Metadata is an exported userguides.GuideMetadata.
+type Metadata struct {
+	Title             string   `json:"title"`
+	Description       string   `json:"description"`
+	Labels            []string `json:"labels"`
+	Difficulty        string   `json:"difficulty"`
+	MinutesToComplete int      `json:"minutesToComplete"`
+	Prerequisites     []string `json:"prerequisites"`
+}
+
+// Step is an exported userguides.GuideStep. Instruction holds the template,
+// with its ${name} placeholders unrendered.
+type Step struct {
+	Order          int    `json:"order"`
+	Title          string `json:"title"`
+	Instruction    string `json:"instruction"`
+	Hint           string `json:"hint"`
+	ValidationHint string `json:"validationHint"`
+	Validation     string `json:"validation"`
+	Docs           []Doc  `json:"docs"`
+}
+
+// Doc is an exported userguides.GuideDoc.
+type Doc struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Completion is an exported userguides.GuideCompletion.
+type Completion struct {
+	SuccessMessage      string   `json:"successMessage"`
+	RecommendedGuideIDs []string `json:"recommendedGuideIds"`
+}
+
+// New converts lib into a Document. Groups, chapters and guides are sorted
+// by ordering, with slugs breaking ties, steps by order and variables by
+// name. Labels, prerequisites, docs and references keep the order of the
+// guide file.
+func New(lib *userguides.Library) *Document {
+	doc := &Document{
+		FormatVersion: FormatVersion,
+		Groups:        make([]Group, 0, len(lib.Groups)),
+	}
+	for _, group := range lib.Groups {
+		doc.Groups = append(doc.Groups, newGroup(group))
+	}
+	slices.SortStableFunc(doc.Groups, func(a, b Group) int {
+		return cmp.Or(cmp.Compare(a.Ordering, b.Ordering), cmp.Compare(a.Slug, b.Slug))
+	})
+	return doc
+}
+
+// Write writes the document exported from lib to w as indented JSON.
+// HTML characters, which are common in the markdown instructions, are not
+// escaped.
+func Write(w io.Writer, lib *userguides.Library) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(New(lib))
+}
+
+func newGroup(group userguides.Group) Group {
+	g := Group{
+		Slug:        group.Slug,
+		Name:        group.Name,
+		Description: group.Description,
+		SkillLevel:  group.SkillLevel,
+		Ordering:    group.Ordering,
+		Chapters:    make([]Chapter, 0, len(group.Chapters)),
+	}
+	for _, chapter := range group.Chapters {
+		g.Chapters = append(g.Chapters, newChapter(chapter))
+	}
+	slices.SortStableFunc(g.Chapters, func(a, b Chapter) int {
+		return cmp.Or(cmp.Compare(a.Ordering, b.Ordering), cmp.Compare(a.Slug, b.Slug))
+	})
+	return g
+}
+
+func newChapter(chapter userguides.Chapter) Chapter {
+	c := Chapter{
+		Slug:        chapter.Slug,
+		Name:        chapter.Name,
+		Description: chapter.Description,
+		Ordering:    chapter.Ordering,
+		Variables:   make([]Variable, 0, len(chapter.Variables)),
+		Guides:      make([]Guide, 0, len(chapter.Guides)),
+	}
+	for _, v := range chapter.Variables {
+		c.Variables = append(c.Variables, Variable{
+			Name:         v.Name,
+			Description:  v.Description,
+			ResourceType: string(v.ResourceType),
+		})
+	}
+	slices.SortStableFunc(c.Variables, func(a, b Variable) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, guide := range chapter.Guides {
+		c.Guides = append(c.Guides, NewGuide(guide))
+	}
+	slices.SortStableFunc(c.Guides, func(a, b Guide) int {
+		return cmp.Or(cmp.Compare(a.Ordering, b.Ordering), cmp.Compare(a.Slug, b.Slug))
+	})
+	return c
+}
+
+// NewGuide converts a single guide, such as one returned by
+// userguides.Library.Render. Its steps are sorted by order.
+func NewGuide(guide userguides.Guide) Guide {
+	releaseState := guide.ReleaseState
+	if releaseState == "" {
+		releaseState = userguides.ReleaseStatePublished
+	}
+
+	g := Guide{
+		Slug:                   guide.Slug,
+		Ordering:               guide.Ordering,
+		ReleaseState:           string(releaseState),
+		PrerequisiteGuideSlugs: nonNil(guide.PrerequisiteGuideSlugs),
+		Metadata: Metadata{
+			Title:             guide.Metadata.Title,
+			Description:       guide.Metadata.Description,
+			Labels:            nonNil(guide.Metadata.Labels),
+			Difficulty:        guide.Metadata.Difficulty,
+			MinutesToComplete: guide.Metadata.MinutesToComplete,
+			Prerequisites:     nonNil(guide.Metadata.Prerequisites),
+		},
+		Steps: make([]Step, 0, len(guide.Steps)),
+		Completion: Completion{
+			SuccessMessage:      guide.Completion.SuccessMessage,
+			RecommendedGuideIDs: nonNil(guide.Completion.RecommendedGuideIDs),
+		},
+	}
+	for _, step := range guide.Steps {
+		s := Step{
+			Order:          step.Order,
+			Title:          step.Title,
+			Instruction:    step.Instruction,
+			Hint:           step.Hint,
+			ValidationHint: step.ValidationHint,
+			Validation:     step.Validation,
+			Docs:           make([]Doc, 0, len(step.Docs)),
+		}
+		for _, doc := range step.Docs {
+			s.Docs = append(s.Docs, Doc{Title: doc.Title, URL: doc.URL})
+		}
+		g.Steps = append(g.Steps, s)
+	}
+	slices.SortStableFunc(g.Steps, func(a, b Step) int {
+		return cmp.Compare(a.Order, b.Order)
+	})
+	return g
+}
+
+// nonNil returns a copy of s that encodes as [] rather than null when s is
+// empty.
+func nonNil(s []string) []string {
+	return append([]string{}, s...)
+}
@@ -0,0 +1,90 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const golden = "testdata/library.json"
+
+func loadTestdata(t *testing.T) *userguides.Library {
+	t.Helper()
+
+	lib, err := userguides.Load(os.DirFS("testdata"))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	return lib
+}
+
+func TestWrite_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, loadTestdata(t)); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("the export differs from %s, run go test ./export -update and review the diff; got:\n%s", golden, buf.Bytes())
+	}
+}
+
+func TestNew_SortsHandBuiltLibraries(t *testing.T) {
+	lib := loadTestdata(t)
+
+	// Reverse every level of a copy, as a library assembled by hand might be.
+	shuffled := &userguides.Library{Groups: []userguides.Group{lib.Groups[1], lib.Groups[0]}}
+	basics := &shuffled.Groups[1]
+	basics.Chapters = []userguides.Chapter{basics.Chapters[1], basics.Chapters[0]}
+	intro := &basics.Chapters[1]
+	guide := intro.Guides[0]
+	guide.Steps = []userguides.GuideStep{guide.Steps[1], guide.Steps[0]}
+	intro.Guides = []userguides.Guide{guide}
+
+	want, err := json.Marshal(export.New(lib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(export.New(shuffled))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected the export not to depend on the order of the library, got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNew_EmptyLists(t *testing.T) {
+	lib := &userguides.Library{Groups: []userguides.Group{{
+		Slug:     "group",
+		Chapters: []userguides.Chapter{{Slug: "chapter", Guides: []userguides.Guide{{Slug: "guide"}}}},
+	}}}
+
+	data, err := json.Marshal(export.New(lib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("null")) {
+		t.Errorf("expected unset lists to be exported as [], got: %s", data)
+	}
+	if !bytes.Contains(data, []byte(`"releaseState":"published"`)) {
+		t.Errorf("expected guides without a release state to be published, got: %s", data)
+	}
+}
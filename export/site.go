@@ -0,0 +1,310 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// SiteOptions configures MarkdownSite and HTMLSite.
+type SiteOptions struct {
+	// BaseURL is prepended to in-app links, such as [Policies](/policies),
+	// which are relative to the Spacelift app. They stay relative if it is
+	// empty.
+	BaseURL string
+	// Values render the ${name} placeholders of chapter variables, as
+	// Chapter.Render does. Placeholders without a value are left in place.
+	Values map[string]string
+}
+
+// Page is a file of an exported site.
+type Page struct {
+	// Path is relative to the root of the site, e.g. "index.md" or
+	// "guides/ground-control-first-stack.md".
+	Path    string
+	Content []byte
+}
+
+// MarkdownSite converts lib into Markdown pages: index.md, which lists the
+// groups, chapters and guides, and one page per guide in the guides
+// directory, named after its slug, so that no slug can clash with the
+// index. Guide pages hold the numbered steps, with hints and validation
+// hints as callouts and documentation links as lists, the prerequisites
+// and the recommended next guides.
+//
+// Code fences are rewritten from the app's ```language-hcl to ```hcl, and
+// in-app links, inline or reference-style, are prefixed with opts.BaseURL.
+func MarkdownSite(lib *userguides.Library, opts SiteOptions) []Page {
+	return newSite(lib, opts, ".md").pages()
+}
+
+// HTMLSite converts lib into the pages of MarkdownSite as standalone HTML
+// documents, linking to each other with the .html extension.
+func HTMLSite(lib *userguides.Library, opts SiteOptions) ([]Page, error) {
+	s := newSite(lib, opts, ".html")
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	pages := s.pages()
+	for i, page := range pages {
+		var body bytes.Buffer
+		if err := md.Convert(page.Content, &body); err != nil {
+			return nil, fmt.Errorf("%s: %w", page.Path, err)
+		}
+		var out bytes.Buffer
+		err := htmlPage.Execute(&out, map[string]any{
+			"Title": s.titles[page.Path],
+			"Body":  template.HTML(body.String()),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", page.Path, err)
+		}
+		pages[i].Content = out.Bytes()
+	}
+	return pages, nil
+}
+
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+{{.Body}}</body>
+</html>
+`))
+
+const (
+	// indexTitle is the title of the index page.
+	indexTitle = "User Guides"
+	// guidesDir is the directory of the guide pages.
+	guidesDir = "guides"
+)
+
+// site is the library being converted to pages whose names end in ext.
+type site struct {
+	doc    *Document
+	opts   SiteOptions
+	ext    string
+	guides map[string]*Guide
+	titles map[string]string
+}
+
+func newSite(lib *userguides.Library, opts SiteOptions, ext string) *site {
+	s := &site{
+		doc:    New(renderLibrary(lib, opts.Values)),
+		opts:   opts,
+		ext:    ext,
+		guides: make(map[string]*Guide),
+		titles: map[string]string{"index" + ext: indexTitle},
+	}
+	for _, group := range s.doc.Groups {
+		for _, chapter := range group.Chapters {
+			for i := range chapter.Guides {
+				guide := &chapter.Guides[i]
+				s.guides[guide.Slug] = guide
+				s.titles[s.pagePath(guide.Slug)] = guide.Metadata.Title
+			}
+		}
+	}
+	return s
+}
+
+// renderLibrary returns a copy of lib with every guide rendered with the
+// values of the variables its chapter declares.
+func renderLibrary(lib *userguides.Library, values map[string]string) *userguides.Library {
+	rendered := &userguides.Library{Groups: append([]userguides.Group(nil), lib.Groups...)}
+	for i := range rendered.Groups {
+		group := &rendered.Groups[i]
+		group.Chapters = append([]userguides.Chapter(nil), group.Chapters...)
+		for j := range group.Chapters {
+			chapter := &group.Chapters[j]
+			declared := make(map[string]string)
+			for _, v := range chapter.Variables {
+				if value, ok := values[v.Name]; ok {
+					declared[v.Name] = value
+				}
+			}
+			chapter.Guides = append([]userguides.Guide(nil), chapter.Guides...)
+			for k := range chapter.Guides {
+				// The only errors left are missing values, whose
+				// placeholders stay in place.
+				chapter.Guides[k], _ = chapter.Render(chapter.Guides[k], declared)
+			}
+		}
+	}
+	return rendered
+}
+
+// pagePath is the path of the page of the guide with the given slug.
+func (s *site) pagePath(slug string) string {
+	return guidesDir + "/" + slug + s.ext
+}
+
+func (s *site) pages() []Page {
+	pages := []Page{{Path: "index" + s.ext, Content: s.index()}}
+	for _, group := range s.doc.Groups {
+		for _, chapter := range group.Chapters {
+			for _, guide := range chapter.Guides {
+				pages = append(pages, Page{
+					Path:    s.pagePath(guide.Slug),
+					Content: s.guidePage(group, chapter, guide),
+				})
+			}
+		}
+	}
+	return pages
+}
+
+func (s *site) index() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", indexTitle)
+	for _, group := range s.doc.Groups {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", group.Name, s.markdown(group.Description))
+		for _, chapter := range group.Chapters {
+			fmt.Fprintf(&b, "\n### %s\n\n%s\n\n", chapter.Name, s.markdown(chapter.Description))
+			for _, guide := range chapter.Guides {
+				fmt.Fprintf(&b, "%d. [%s](%s): %s (%d min)\n", guide.Ordering, guide.Metadata.Title, s.pagePath(guide.Slug), s.markdown(guide.Metadata.Description), guide.Metadata.MinutesToComplete)
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+func (s *site) guidePage(group Group, chapter Chapter, guide Guide) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", guide.Metadata.Title)
+	fmt.Fprintf(&b, "[%s](%s) › %s › %s\n\n", indexTitle, "../index"+s.ext, group.Name, chapter.Name)
+	fmt.Fprintf(&b, "%s\n\n", s.markdown(guide.Metadata.Description))
+	fmt.Fprintf(&b, "**Difficulty:** %s · **Time:** %d min · **Skill level:** %s\n", guide.Metadata.Difficulty, guide.Metadata.MinutesToComplete, group.SkillLevel)
+
+	if len(guide.Metadata.Prerequisites) > 0 || len(guide.PrerequisiteGuideSlugs) > 0 {
+		b.WriteString("\n## Before You Start\n\n")
+		for _, prerequisite := range guide.Metadata.Prerequisites {
+			fmt.Fprintf(&b, "- %s\n", s.markdown(prerequisite))
+		}
+		for _, slug := range guide.PrerequisiteGuideSlugs {
+			fmt.Fprintf(&b, "- Complete %s\n", s.guideLink(slug))
+		}
+	}
+
+	for _, step := range guide.Steps {
+		fmt.Fprintf(&b, "\n## %d. %s\n\n%s\n", step.Order, step.Title, strings.TrimRight(s.markdown(step.Instruction), "\n"))
+		if step.Hint != "" {
+			callout(&b, "Hint", s.markdown(step.Hint))
+		}
+		if step.ValidationHint != "" {
+			callout(&b, "Before you continue", s.markdown(step.ValidationHint))
+		}
+		if len(step.Docs) > 0 {
+			b.WriteString("\nDocumentation:\n\n")
+			for _, doc := range step.Docs {
+				fmt.Fprintf(&b, "- [%s](%s)\n", doc.Title, doc.URL)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Next Steps\n\n%s\n", strings.TrimRight(s.markdown(guide.Completion.SuccessMessage), "\n"))
+	if len(guide.Completion.RecommendedGuideIDs) > 0 {
+		b.WriteString("\n")
+		for _, slug := range guide.Completion.RecommendedGuideIDs {
+			fmt.Fprintf(&b, "- %s\n", s.guideLink(slug))
+		}
+	}
+	return []byte(b.String())
+}
+
+// guideLink links from a guide page to the page of the guide with the
+// given slug, or names the slug if the guide is not exported.
+func (s *site) guideLink(slug string) string {
+	guide, ok := s.guides[slug]
+	if !ok {
+		return "`" + slug + "`"
+	}
+	return fmt.Sprintf("[%s](%s)", guide.Metadata.Title, slug+s.ext)
+}
+
+// callout writes text as a blockquote that starts with a bold label.
+func callout(b *strings.Builder, label, text string) {
+	fmt.Fprintf(b, "\n> **%s:** ", label)
+	for i, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		switch {
+		case i == 0:
+			b.WriteString(line)
+		case line == "":
+			b.WriteString("\n>")
+		default:
+			b.WriteString("\n> " + line)
+		}
+	}
+	b.WriteString("\n")
+}
+
+var (
+	// fence matches the opening of a code fence and its info string.
+	fence = regexp.MustCompile("^(\\s*)(```+|~~~+)(.*)$")
+	// appLinks match the destination of a Markdown link to a path of the
+	// app, but not a protocol-relative (//host) one: inline, as in
+	// (/policies) or (/policies "Policies"), and in a reference definition,
+	// as in [policies]: /policies. The path is the first submatch.
+	appLinks = []*regexp.Regexp{
+		regexp.MustCompile(`\]\((/(?:[^/)\s][^)\s]*)?)(?:\)|\s)`),
+		regexp.MustCompile(`^ {0,3}\[[^\]]+\]:[ \t]*(/(?:[^/\s]\S*)?)(?:\s|$)`),
+	}
+)
+
+// markdown converts guide Markdown for the site: it rewrites the
+// language-<name> info strings of code fences to <name> and, outside of
+// code fences, prefixes in-app links with the base URL.
+func (s *site) markdown(text string) string {
+	lines := strings.Split(text, "\n")
+	var open string
+	for i, line := range lines {
+		if m := fence.FindStringSubmatch(line); m != nil {
+			switch {
+			case open == "":
+				open = m[2]
+				lines[i] = m[1] + m[2] + strings.TrimPrefix(m[3], "language-")
+			case strings.HasPrefix(m[2], open) && strings.TrimSpace(m[3]) == "":
+				open = ""
+			}
+			continue
+		}
+		if open == "" && s.opts.BaseURL != "" {
+			lines[i] = s.prefixAppLinks(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prefixAppLinks prefixes the in-app link destinations in line with the
+// base URL.
+func (s *site) prefixAppLinks(line string) string {
+	var starts []int
+	for _, re := range appLinks {
+		for _, m := range re.FindAllStringSubmatchIndex(line, -1) {
+			starts = append(starts, m[2])
+		}
+	}
+	slices.Sort(starts)
+
+	base := strings.TrimSuffix(s.opts.BaseURL, "/")
+	var b strings.Builder
+	last := 0
+	for _, start := range starts {
+		b.WriteString(line[last:start])
+		b.WriteString(base)
+		last = start
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package export_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+)
+
+var siteOptions = export.SiteOptions{
+	BaseURL: "https://example.app.spacelift.io/",
+	Values:  map[string]string{"stack_name": "prod", "unknown": "ignored"},
+}
+
+func TestMarkdownSite_Golden(t *testing.T) {
+	pages := export.MarkdownSite(loadTestdata(t), siteOptions)
+
+	var paths []string
+	for _, page := range pages {
+		paths = append(paths, page.Path)
+
+		path := filepath.Join("testdata/site", page.Path)
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, page.Content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(page.Content, want) {
+			t.Errorf("%s differs from %s, run go test ./export -update and review the diff; got:\n%s", page.Path, path, page.Content)
+		}
+	}
+
+	if got, want := strings.Join(paths, ","), "index.md,guides/create-stack.md,guides/plan-policy.md,guides/enable-drift.md"; got != want {
+		t.Errorf("expected the pages %s in display order, got %s", want, got)
+	}
+}
+
+func TestMarkdownSite_RelativeLinks(t *testing.T) {
+	pages := export.MarkdownSite(loadTestdata(t), export.SiteOptions{})
+
+	content := string(pages[1].Content)
+	for _, want := range []string{"[Stacks](/stacks)", `(/stacks "Stacks")`, "[contexts]: /contexts", "${stack_name}", "```hcl\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected the page to contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestHTMLSite(t *testing.T) {
+	pages, err := export.HTMLSite(loadTestdata(t), siteOptions)
+	if err != nil {
+		t.Fatalf("HTMLSite returned error: %v", err)
+	}
+	if len(pages) != 4 || pages[0].Path != "index.html" || pages[1].Path != "guides/create-stack.html" {
+		t.Fatalf("expected an index and three guide pages, got %d pages", len(pages))
+	}
+
+	content := string(pages[1].Content)
+	for _, want := range []string{
+		"<title>Create a Stack</title>",
+		`<h2>1. Create the stack</h2>`,
+		`<a href="https://example.app.spacelift.io/stacks">Stacks</a>`,
+		`<code class="language-hcl">`,
+		`<blockquote>`,
+		`<a href="plan-policy.html">Plan Policy</a>`,
+		`<a href="../index.html">User Guides</a>`,
+		`<a href="https://example.app.spacelift.io/stacks" title="Stacks">its stack</a>`,
+		`<a href="https://example.app.spacelift.io/contexts">contexts page</a>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected the page to contain %s, got:\n%s", want, content)
+		}
+	}
+	if index := string(pages[0].Content); !strings.Contains(index, `<a href="guides/enable-drift.html">Enable Drift Detection</a>`) {
+		t.Errorf("expected the index to link to every guide, got:\n%s", index)
+	}
+}
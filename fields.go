@@ -0,0 +1,135 @@
+package userguides
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkKnownFields reports every mapping key in the decoded file name that
+// does not correspond to a field of t, the type the file was decoded into.
+// yaml.v3 silently drops such keys, so a misspelt `validaton:` would
+// otherwise ship a step without its validation.
+func (l *loader) checkKnownFields(name string, t reflect.Type) {
+	doc := l.docs[name]
+	if doc == nil || len(doc.Content) == 0 {
+		return
+	}
+
+	l.check(name, func(r *ValidationReport) {
+		unknownFields(r, doc.Content[0], t, nil)
+	})
+}
+
+func unknownFields(r *ValidationReport, node *yaml.Node, t reflect.Type, field []any) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			at := append(slices.Clone(field), key)
+
+			ft, ok := fields[key]
+			if !ok {
+				msg := fmt.Sprintf("unknown field %q", key)
+				if s := suggest(key, knownNames(fields)); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", s)
+				}
+				r.errorf(CodeFieldUnknown, append(at, mappingKey{}), "%s", atField(field, msg))
+				continue
+			}
+			unknownFields(r, value, ft, at)
+		}
+	case node.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for i, item := range node.Content {
+			unknownFields(r, item, t.Elem(), append(slices.Clone(field), i))
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of struct type t to the types of their
+// fields.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func knownNames(fields map[string]reflect.Type) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// suggest returns the candidate closest to name, ignoring case, or "" when
+// none is close enough to be a likely typo.
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(name), strings.ToLower(c))
+		if d > 2 && d*3 > len(c) {
+			continue
+		}
+		if best == "" || d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn one into the other.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,74 @@
+package userguides
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestKnownFields_UnknownKeys(t *testing.T) {
+	guide := strings.Replace(string(guideYAMLWithValidation("package spacelift\n\nvalid if { true }\n")), "    validation: |", "    validaton: |", 1)
+	guide = strings.Replace(guide, "minutesToComplete: 5", "minutesToCompelte: 5", 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: append(validGroupYAML(), "colour: \"blue\"\n"...)},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	problems := loadProblems(t, f)
+
+	want := []struct {
+		path    string
+		line    int
+		column  int
+		message string
+	}{
+		{"guides/mygroup/group.yaml", 5, 1, `unknown field "colour"`},
+		{"guides/mygroup/mychapter/guide-one.yaml", 8, 3, `metadata: unknown field "minutesToCompelte", did you mean "minutesToComplete"?`},
+		{"guides/mygroup/mychapter/guide-one.yaml", 15, 5, `steps[0]: unknown field "validaton", did you mean "validation"?`},
+	}
+
+	var got []Problem
+	for _, p := range problems {
+		if p.Code == CodeFieldUnknown {
+			got = append(got, p)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d unknown fields, got: %v", len(want), problems)
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.Path != w.path || p.Line != w.line || p.Column != w.column || p.Message != w.message {
+			t.Errorf("expected %s:%d:%d: %s, got %s:%d:%d: %s", w.path, w.line, w.column, w.message, p.Path, p.Line, p.Column, p.Message)
+		}
+	}
+
+	// The schema rejects the same keys; they must not be reported twice.
+	for _, p := range problems {
+		if p.Code == CodeSchemaViolation && strings.Contains(p.Message, "additional properties") {
+			t.Errorf("expected the unknown field not to be reported by the schema as well, got: %v", p)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"hint", "instruction", "order", "title", "validation", "validationHint"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"validaton", "validation"},
+		{"validationhint", "validationHint"},
+		{"titel", "title"},
+		{"instructions", "instruction"},
+		{"description", ""},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		if got := suggest(tt.name, candidates); got != tt.want {
+			t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
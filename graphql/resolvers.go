@@ -0,0 +1,454 @@
+// Package graphql publishes the GraphQL schema of the guide hierarchy and
+// resolvers that serve it from a library.
+//
+// The resolvers follow the conventions of github.com/graph-gophers/graphql-go:
+// one method per field, arguments in a struct, Int as int32, enums as
+// strings and nullable fields as pointers. Other executors can call them
+// from their own resolvers.
+//
+//	schema := graphql.MustParseSchema(guidegraphql.Schema, guidegraphql.NewResolver(lib), graphql.UseStringDescriptions())
+//
+// The resolvers serve whatever library they are given; pass
+// lib.Filter(userguides.ReleaseStatePublished) to hide guides under test.
+// References to guides that are not in the library, which loading without
+// cross-reference checks allows, are left out of prerequisiteGuides and
+// recommendedGuides.
+package graphql
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// Schema is the GraphQL schema, in SDL, that Resolver implements. Its
+// descriptions are block strings.
+//
+//go:embed schema.graphql
+var Schema string
+
+// Resolver is the root resolver: it resolves the fields of Query.
+type Resolver struct {
+	lib *userguides.Library
+}
+
+// NewResolver returns a resolver serving lib, which must have been returned
+// by userguides.Load, Guides or Library.Filter.
+func NewResolver(lib *userguides.Library) *Resolver {
+	return &Resolver{lib: lib}
+}
+
+// GroupsArgs are the arguments of Query.groups.
+type GroupsArgs struct {
+	SkillLevel *string
+}
+
+func (r *Resolver) Groups(args GroupsArgs) []*Group {
+	groups := []*Group{}
+	for i := range r.lib.Groups {
+		group := &r.lib.Groups[i]
+		if args.SkillLevel == nil || group.SkillLevel == *args.SkillLevel {
+			groups = append(groups, &Group{r: r, group: group})
+		}
+	}
+	return groups
+}
+
+// GroupArgs are the arguments of Query.group.
+type GroupArgs struct {
+	Slug string
+}
+
+func (r *Resolver) Group(args GroupArgs) *Group {
+	group, ok := r.lib.GroupBySlug(args.Slug)
+	if !ok {
+		return nil
+	}
+	return &Group{r: r, group: group}
+}
+
+// ChapterArgs are the arguments of Query.chapter.
+type ChapterArgs struct {
+	GroupSlug string
+	Slug      string
+}
+
+func (r *Resolver) Chapter(args ChapterArgs) *Chapter {
+	group, ok := r.lib.GroupBySlug(args.GroupSlug)
+	if !ok {
+		return nil
+	}
+	chapter, ok := r.lib.ChapterByPath(args.GroupSlug, args.Slug)
+	if !ok {
+		return nil
+	}
+	return &Chapter{r: r, group: group, chapter: chapter}
+}
+
+// GuideArgs are the arguments of Query.guide.
+type GuideArgs struct {
+	Slug string
+}
+
+func (r *Resolver) Guide(args GuideArgs) *Guide {
+	return r.guide(args.Slug)
+}
+
+// guide returns the resolver of the guide with the given slug, or nil if
+// the library does not have it.
+func (r *Resolver) guide(slug string) *Guide {
+	guide, ok := r.lib.GuideBySlug(slug)
+	if !ok {
+		return nil
+	}
+	return &Guide{r: r, guide: guide}
+}
+
+// guides returns the resolvers of the guides with the given slugs, leaving
+// out those the library does not have.
+func (r *Resolver) guides(slugs []string) []*Guide {
+	guides := []*Guide{}
+	for _, slug := range slugs {
+		if guide := r.guide(slug); guide != nil {
+			guides = append(guides, guide)
+		}
+	}
+	return guides
+}
+
+// GuidesArgs are the arguments of Query.guides.
+type GuidesArgs struct {
+	Filter *GuideFilter
+	First  *int32
+	After  *string
+}
+
+// GuideFilter is the GuideFilter input.
+type GuideFilter struct {
+	SkillLevel *string
+	Labels     *[]string
+}
+
+// matches reports whether the guide in group matches every field of the
+// filter that is set.
+func (f *GuideFilter) matches(group *userguides.Group, guide *userguides.Guide) bool {
+	if f == nil {
+		return true
+	}
+	if f.SkillLevel != nil && group.SkillLevel != *f.SkillLevel {
+		return false
+	}
+	if f.Labels != nil {
+		for _, label := range *f.Labels {
+			if !slices.Contains(guide.Metadata.Labels, label) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func (r *Resolver) Guides(args GuidesArgs) (*GuideConnection, error) {
+	if args.First != nil && *args.First < 0 {
+		return nil, fmt.Errorf("first must not be negative, got %d", *args.First)
+	}
+
+	var matching []*userguides.Guide
+	for i := range r.lib.Groups {
+		group := &r.lib.Groups[i]
+		for j := range group.Chapters {
+			chapter := &group.Chapters[j]
+			for k := range chapter.Guides {
+				if guide := &chapter.Guides[k]; args.Filter.matches(group, guide) {
+					matching = append(matching, guide)
+				}
+			}
+		}
+	}
+
+	start := 0
+	if args.After != nil {
+		slug, err := decodeCursor(*args.After)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(matching, func(guide *userguides.Guide) bool {
+			return guide.Slug == slug
+		})
+		if i < 0 {
+			return nil, errInvalidCursor
+		}
+		start = i + 1
+	}
+	end := len(matching)
+	if args.First != nil {
+		end = min(end, start+int(*args.First))
+	}
+
+	conn := &GuideConnection{
+		edges:       []*GuideEdge{},
+		hasNextPage: end < len(matching),
+		totalCount:  int32(len(matching)),
+	}
+	for _, guide := range matching[start:end] {
+		conn.edges = append(conn.edges, &GuideEdge{node: &Guide{r: r, guide: guide}})
+	}
+	return conn, nil
+}
+
+// A cursor is the base64 encoding of a guide slug, which is unique across
+// the library. Clients must treat it as opaque.
+const cursorPrefix = "guide:"
+
+func encodeCursor(slug string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + slug))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errInvalidCursor
+	}
+	slug, ok := strings.CutPrefix(string(data), cursorPrefix)
+	if !ok {
+		return "", errInvalidCursor
+	}
+	return slug, nil
+}
+
+// GuideConnection is a page of Query.guides.
+type GuideConnection struct {
+	edges       []*GuideEdge
+	hasNextPage bool
+	totalCount  int32
+}
+
+func (c *GuideConnection) Edges() []*GuideEdge {
+	return c.edges
+}
+
+func (c *GuideConnection) PageInfo() *PageInfo {
+	info := &PageInfo{hasNextPage: c.hasNextPage}
+	if len(c.edges) > 0 {
+		cursor := c.edges[len(c.edges)-1].Cursor()
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+func (c *GuideConnection) TotalCount() int32 {
+	return c.totalCount
+}
+
+type GuideEdge struct {
+	node *Guide
+}
+
+func (e *GuideEdge) Cursor() string {
+	return encodeCursor(e.node.guide.Slug)
+}
+
+func (e *GuideEdge) Node() *Guide {
+	return e.node
+}
+
+type PageInfo struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *PageInfo) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *PageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+type Group struct {
+	r     *Resolver
+	group *userguides.Group
+}
+
+func (g *Group) Slug() string        { return g.group.Slug }
+func (g *Group) Name() string        { return g.group.Name }
+func (g *Group) Description() string { return g.group.Description }
+func (g *Group) SkillLevel() string  { return g.group.SkillLevel }
+func (g *Group) Ordering() int32     { return int32(g.group.Ordering) }
+
+func (g *Group) Chapters() []*Chapter {
+	chapters := make([]*Chapter, 0, len(g.group.Chapters))
+	for i := range g.group.Chapters {
+		chapters = append(chapters, &Chapter{r: g.r, group: g.group, chapter: &g.group.Chapters[i]})
+	}
+	return chapters
+}
+
+type Chapter struct {
+	r       *Resolver
+	group   *userguides.Group
+	chapter *userguides.Chapter
+}
+
+func (c *Chapter) Slug() string        { return c.chapter.Slug }
+func (c *Chapter) Name() string        { return c.chapter.Name }
+func (c *Chapter) Description() string { return c.chapter.Description }
+func (c *Chapter) Ordering() int32     { return int32(c.chapter.Ordering) }
+func (c *Chapter) Group() *Group       { return &Group{r: c.r, group: c.group} }
+
+func (c *Chapter) Variables() []*GuideVariable {
+	variables := make([]*GuideVariable, 0, len(c.chapter.Variables))
+	for i := range c.chapter.Variables {
+		variables = append(variables, &GuideVariable{variable: &c.chapter.Variables[i]})
+	}
+	return variables
+}
+
+func (c *Chapter) Guides() []*Guide {
+	guides := make([]*Guide, 0, len(c.chapter.Guides))
+	for i := range c.chapter.Guides {
+		guides = append(guides, &Guide{r: c.r, guide: &c.chapter.Guides[i]})
+	}
+	return guides
+}
+
+type GuideVariable struct {
+	variable *userguides.GuideVariable
+}
+
+func (v *GuideVariable) Name() string        { return v.variable.Name }
+func (v *GuideVariable) Description() string { return v.variable.Description }
+
+// ResourceType returns the resource type as an enum value: aws_integration
+// is AWS_INTEGRATION.
+func (v *GuideVariable) ResourceType() string {
+	return strings.ToUpper(string(v.variable.ResourceType))
+}
+
+type Guide struct {
+	r     *Resolver
+	guide *userguides.Guide
+}
+
+func (g *Guide) Slug() string    { return g.guide.Slug }
+func (g *Guide) Ordering() int32 { return int32(g.guide.Ordering) }
+
+// ReleaseState returns the release state as an enum value, PUBLISHED for
+// guides without one.
+func (g *Guide) ReleaseState() string {
+	if g.guide.ReleaseState == "" {
+		return "PUBLISHED"
+	}
+	return strings.ToUpper(string(g.guide.ReleaseState))
+}
+
+func (g *Guide) Group() *Group {
+	group, _, _ := g.r.lib.ParentOf(g.guide.Slug)
+	return &Group{r: g.r, group: group}
+}
+
+func (g *Guide) Chapter() *Chapter {
+	group, chapter, _ := g.r.lib.ParentOf(g.guide.Slug)
+	return &Chapter{r: g.r, group: group, chapter: chapter}
+}
+
+func (g *Guide) Metadata() *GuideMetadata {
+	return &GuideMetadata{metadata: &g.guide.Metadata}
+}
+
+func (g *Guide) Steps() []*GuideStep {
+	steps := make([]*GuideStep, 0, len(g.guide.Steps))
+	for i := range g.guide.Steps {
+		steps = append(steps, &GuideStep{step: &g.guide.Steps[i]})
+	}
+	return steps
+}
+
+func (g *Guide) Completion() *GuideCompletion {
+	return &GuideCompletion{r: g.r, completion: &g.guide.Completion}
+}
+
+func (g *Guide) PrerequisiteGuideSlugs() []string {
+	return nonNil(g.guide.PrerequisiteGuideSlugs)
+}
+
+func (g *Guide) PrerequisiteGuides() []*Guide {
+	return g.r.guides(g.guide.PrerequisiteGuideSlugs)
+}
+
+type GuideMetadata struct {
+	metadata *userguides.GuideMetadata
+}
+
+func (m *GuideMetadata) Title() string            { return m.metadata.Title }
+func (m *GuideMetadata) Description() string      { return m.metadata.Description }
+func (m *GuideMetadata) Labels() []string         { return nonNil(m.metadata.Labels) }
+func (m *GuideMetadata) Difficulty() string       { return strings.ToUpper(m.metadata.Difficulty) }
+func (m *GuideMetadata) MinutesToComplete() int32 { return int32(m.metadata.MinutesToComplete) }
+func (m *GuideMetadata) Prerequisites() []string  { return nonNil(m.metadata.Prerequisites) }
+
+type GuideStep struct {
+	step *userguides.GuideStep
+}
+
+func (s *GuideStep) Order() int32            { return int32(s.step.Order) }
+func (s *GuideStep) Title() string           { return s.step.Title }
+func (s *GuideStep) Instruction() string     { return s.step.Instruction }
+func (s *GuideStep) Hint() *string           { return optional(s.step.Hint) }
+func (s *GuideStep) ValidationHint() *string { return optional(s.step.ValidationHint) }
+func (s *GuideStep) Validation() *string     { return optional(s.step.Validation) }
+
+func (s *GuideStep) Docs() []*GuideDoc {
+	docs := make([]*GuideDoc, 0, len(s.step.Docs))
+	for i := range s.step.Docs {
+		docs = append(docs, &GuideDoc{doc: &s.step.Docs[i]})
+	}
+	return docs
+}
+
+type GuideDoc struct {
+	doc *userguides.GuideDoc
+}
+
+func (d *GuideDoc) Title() string { return d.doc.Title }
+func (d *GuideDoc) URL() string   { return d.doc.URL }
+
+type GuideCompletion struct {
+	r          *Resolver
+	completion *userguides.GuideCompletion
+}
+
+func (c *GuideCompletion) SuccessMessage() string {
+	return c.completion.SuccessMessage
+}
+
+func (c *GuideCompletion) RecommendedGuideIDs() []string {
+	return nonNil(c.completion.RecommendedGuideIDs)
+}
+
+func (c *GuideCompletion) RecommendedGuides() []*Guide {
+	return c.r.guides(c.completion.RecommendedGuideIDs)
+}
+
+// optional returns nil for an unset string, which resolves to null.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nonNil returns s, or an empty slice if s is nil.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,214 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	gql "github.com/graph-gophers/graphql-go"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/graphql"
+)
+
+// testLibrary loads testdata/guides, which has two groups: basics holds
+// first-stack and add-policy, advanced holds enable-drift, which is under
+// test and builds on both.
+func testLibrary(t *testing.T) *userguides.Library {
+	t.Helper()
+
+	lib, err := userguides.Load(os.DirFS("testdata"))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	return lib
+}
+
+// execute runs query against the schema served from lib and returns the
+// data as JSON, failing the test on errors.
+func execute(t *testing.T, lib *userguides.Library, query string, variables map[string]any) string {
+	t.Helper()
+
+	resp := executeResponse(t, lib, query, variables)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query returned errors: %v", resp.Errors)
+	}
+	return string(resp.Data)
+}
+
+func executeResponse(t *testing.T, lib *userguides.Library, query string, variables map[string]any) *gql.Response {
+	t.Helper()
+
+	schema, err := gql.ParseSchema(graphql.Schema, graphql.NewResolver(lib), gql.UseStringDescriptions())
+	if err != nil {
+		t.Fatalf("ParseSchema returned error: %v", err)
+	}
+	return schema.Exec(context.Background(), query, "", variables)
+}
+
+func TestSchema_EmbeddedLibrary(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	data := execute(t, lib, `{ groups { chapters { guides { slug group { slug } steps { order } } } } guides { totalCount } }`, nil)
+
+	var result struct {
+		Guides struct {
+			TotalCount int
+		}
+	}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Guides.TotalCount == 0 {
+		t.Errorf("expected the embedded library to have guides, got: %s", data)
+	}
+}
+
+func TestQuery_BySlug(t *testing.T) {
+	lib := testLibrary(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "group",
+			query: `{ group(slug: "basics") { name skillLevel ordering chapters { slug } } }`,
+			want:  `{"group":{"name":"Basics","skillLevel":"BEGINNER","ordering":1,"chapters":[{"slug":"intro"}]}}`,
+		},
+		{
+			name:  "chapter",
+			query: `{ chapter(groupSlug: "basics", slug: "intro") { name group { slug } variables { name resourceType } guides { slug } } }`,
+			want:  `{"chapter":{"name":"Intro","group":{"slug":"basics"},"variables":[{"name":"stack_name","resourceType":"AWS_INTEGRATION"}],"guides":[{"slug":"first-stack"},{"slug":"add-policy"}]}}`,
+		},
+		{
+			name:  "guide",
+			query: `{ guide(slug: "add-policy") { releaseState group { slug } chapter { slug } metadata { labels difficulty minutesToComplete } steps { order instruction hint docs { url } } prerequisiteGuideSlugs prerequisiteGuides { slug } } }`,
+			want:  `{"guide":{"releaseState":"PUBLISHED","group":{"slug":"basics"},"chapter":{"slug":"intro"},"metadata":{"labels":["stacks","policies"],"difficulty":"MEDIUM","minutesToComplete":5},"steps":[{"order":1,"instruction":"Create ${stack_name}","hint":null,"docs":[]}],"prerequisiteGuideSlugs":["first-stack"],"prerequisiteGuides":[{"slug":"first-stack"}]}}`,
+		},
+		{
+			name:  "recommended guides",
+			query: `{ guide(slug: "enable-drift") { releaseState completion { recommendedGuideIds recommendedGuides { slug releaseState } } } }`,
+			want:  `{"guide":{"releaseState":"TESTING","completion":{"recommendedGuideIds":["first-stack","add-policy"],"recommendedGuides":[{"slug":"first-stack","releaseState":"PUBLISHED"},{"slug":"add-policy","releaseState":"PUBLISHED"}]}}}`,
+		},
+		{
+			name:  "missing",
+			query: `{ group(slug: "missing") { name } chapter(groupSlug: "basics", slug: "missing") { name } guide(slug: "missing") { slug } }`,
+			want:  `{"group":null,"chapter":null,"guide":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(t, lib, tt.query, nil); got != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestQuery_Filters(t *testing.T) {
+	lib := testLibrary(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "groups by skill level",
+			query: `{ groups(skillLevel: COMMANDER) { slug } }`,
+			want:  `{"groups":[{"slug":"advanced"}]}`,
+		},
+		{
+			name:  "guides by skill level",
+			query: `{ guides(filter: {skillLevel: BEGINNER}) { totalCount edges { node { slug } } } }`,
+			want:  `{"guides":{"totalCount":2,"edges":[{"node":{"slug":"first-stack"}},{"node":{"slug":"add-policy"}}]}}`,
+		},
+		{
+			name:  "guides with every label",
+			query: `{ guides(filter: {labels: ["stacks", "policies"]}) { totalCount edges { node { slug } } } }`,
+			want:  `{"guides":{"totalCount":1,"edges":[{"node":{"slug":"add-policy"}}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(t, lib, tt.query, nil); got != tt.want {
+				t.Errorf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestQuery_Pagination(t *testing.T) {
+	lib := testLibrary(t)
+	query := `query($after: String) { guides(first: 2, after: $after) { totalCount edges { node { slug } } pageInfo { hasNextPage endCursor } } }`
+
+	type page struct {
+		Guides struct {
+			TotalCount int
+			Edges      []struct {
+				Node struct{ Slug string }
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   *string
+			}
+		}
+	}
+
+	var slugs []string
+	var after any
+	for range 3 {
+		var p page
+		if err := json.Unmarshal([]byte(execute(t, lib, query, map[string]any{"after": after})), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Guides.TotalCount != 3 {
+			t.Errorf("expected a total count of 3 on every page, got %d", p.Guides.TotalCount)
+		}
+		for _, edge := range p.Guides.Edges {
+			slugs = append(slugs, edge.Node.Slug)
+		}
+		if !p.Guides.PageInfo.HasNextPage {
+			break
+		}
+		after = *p.Guides.PageInfo.EndCursor
+	}
+
+	if got, want := strings.Join(slugs, ","), "first-stack,add-policy,enable-drift"; got != want {
+		t.Errorf("expected the pages to hold %s, got %s", want, got)
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	lib := testLibrary(t)
+
+	tests := map[string]string{
+		`{ guides(after: "bogus") { totalCount } }`: "invalid cursor",
+		`{ guides(first: -1) { totalCount } }`:      "first must not be negative",
+	}
+	for query, want := range tests {
+		resp := executeResponse(t, lib, query, nil)
+		if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, want) {
+			t.Errorf("%s: expected an error containing %q, got %v", query, want, resp.Errors)
+		}
+	}
+}
+
+func TestQuery_FilteredLibrary(t *testing.T) {
+	lib := testLibrary(t).Filter(userguides.ReleaseStatePublished)
+
+	got := execute(t, lib, `{ groups { slug } guides { totalCount } guide(slug: "enable-drift") { slug } }`, nil)
+	want := `{"groups":[{"slug":"basics"}],"guides":{"totalCount":2},"guide":null}`
+	if got != want {
+		t.Errorf("expected guides under test to be left out, expected\n%s\ngot\n%s", want, got)
+	}
+}
@@ -0,0 +1,100 @@
+package userguides
+
+// libraryIndex maps slugs to positions in a Library so lookups do not walk
+// the tree. Positions rather than pointers are stored so that the index
+// stays valid for a Library value that has been copied.
+type libraryIndex struct {
+	groups   map[string]int
+	chapters map[chapterKey]chapterLocation
+	guides   map[string]guideLocation
+}
+
+type chapterKey struct {
+	group, chapter string
+}
+
+type chapterLocation struct {
+	group, chapter int
+}
+
+type guideLocation struct {
+	group, chapter, guide int
+}
+
+// newLibraryIndex indexes lib. When slugs are duplicated, which Load only
+// allows with cross-reference checks disabled, the first occurrence wins.
+func newLibraryIndex(lib *Library) *libraryIndex {
+	idx := &libraryIndex{
+		groups:   make(map[string]int),
+		chapters: make(map[chapterKey]chapterLocation),
+		guides:   make(map[string]guideLocation),
+	}
+
+	for gi, group := range lib.Groups {
+		if _, ok := idx.groups[group.Slug]; !ok {
+			idx.groups[group.Slug] = gi
+		}
+		for ci, chapter := range group.Chapters {
+			key := chapterKey{group.Slug, chapter.Slug}
+			if _, ok := idx.chapters[key]; !ok {
+				idx.chapters[key] = chapterLocation{gi, ci}
+			}
+			for ki, guide := range chapter.Guides {
+				if _, ok := idx.guides[guide.Slug]; !ok {
+					idx.guides[guide.Slug] = guideLocation{gi, ci, ki}
+				}
+			}
+		}
+	}
+
+	return idx
+}
+
+// lookup returns the index built by Load. A Library assembled by hand has
+// none, in which case a temporary one is built for the call.
+func (l *Library) lookup() *libraryIndex {
+	if l.index != nil {
+		return l.index
+	}
+	return newLibraryIndex(l)
+}
+
+// GroupBySlug returns the group with the given slug.
+func (l *Library) GroupBySlug(slug string) (*Group, bool) {
+	gi, ok := l.lookup().groups[slug]
+	if !ok {
+		return nil, false
+	}
+	return &l.Groups[gi], true
+}
+
+// ChapterByPath returns the chapter with the given slug inside the group
+// with the given slug. Chapter slugs are only unique within their group.
+func (l *Library) ChapterByPath(groupSlug, chapterSlug string) (*Chapter, bool) {
+	loc, ok := l.lookup().chapters[chapterKey{groupSlug, chapterSlug}]
+	if !ok {
+		return nil, false
+	}
+	return &l.Groups[loc.group].Chapters[loc.chapter], true
+}
+
+// GuideBySlug returns the guide with the given slug. Guide slugs are unique
+// across the whole library.
+func (l *Library) GuideBySlug(slug string) (*Guide, bool) {
+	loc, ok := l.lookup().guides[slug]
+	if !ok {
+		return nil, false
+	}
+	return &l.Groups[loc.group].Chapters[loc.chapter].Guides[loc.guide], true
+}
+
+// ParentOf returns the group and chapter that contain the guide with the
+// given slug.
+func (l *Library) ParentOf(guideSlug string) (*Group, *Chapter, bool) {
+	loc, ok := l.lookup().guides[guideSlug]
+	if !ok {
+		return nil, nil, false
+	}
+	group := &l.Groups[loc.group]
+	return group, &group.Chapters[loc.chapter], true
+}
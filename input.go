@@ -0,0 +1,162 @@
+package userguides
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/spacelift-io/spacelift-user-guides-library/internal/schemagen"
+)
+
+//go:generate go run ./internal/cmd/genschema
+
+// ValidationInput is the document a step validation reads as input. The
+// backend builds it from the user's account, listing the resources the
+// validations inspect; rego_input/sample_rego_input.json is an example.
+//
+// Step validations are type-checked against this model when the library
+// loads, so a validation cannot read a field the backend never sends. A nil
+// collection is left out of the document, but an empty one is sent as [],
+// so that validations can tell "none" from "not listed".
+type ValidationInput struct {
+	Expectations              map[string]string               `json:"expectations,omitempty" description:"Resource name the user chose for each chapter variable, keyed by variable name"`
+	Stacks                    []InputStack                    `json:"stacks,omitzero" description:"Stacks in the account"`
+	Runs                      []InputRun                      `json:"runs,omitzero" description:"Runs of the stacks in the account"`
+	AWSIntegrations           []InputAWSIntegration           `json:"aws_integrations,omitzero" description:"AWS cloud integrations in the account"`
+	AWSAttachments            []InputAWSAttachment            `json:"aws_attachments,omitzero" description:"Attachments of AWS integrations to stacks"`
+	Policies                  []InputPolicy                   `json:"policies,omitzero" description:"Policies in the account"`
+	PolicyAttachments         []InputPolicyAttachment         `json:"policy_attachments,omitzero" description:"Attachments of policies to stacks"`
+	Contexts                  []InputContext                  `json:"contexts,omitzero" description:"Contexts in the account"`
+	ContextAttachments        []InputContextAttachment        `json:"context_attachments,omitzero" description:"Attachments of contexts to stacks"`
+	Spaces                    []InputSpace                    `json:"spaces,omitzero" description:"Spaces in the account"`
+	StackDependencies         []InputStackDependency          `json:"stack_dependencies,omitzero" description:"Dependencies between stacks"`
+	StackDependencyReferences []InputStackDependencyReference `json:"stack_dependency_references,omitzero" description:"Outputs of a stack passed as inputs to a stack that depends on it"`
+}
+
+// InputStack is a stack in ValidationInput.
+type InputStack struct {
+	ID                   string   `json:"id" description:"Stack ID (slug)"`
+	Name                 string   `json:"name" description:"Stack name"`
+	SpaceSlug            string   `json:"space_slug" description:"Slug of the space the stack belongs to"`
+	Autodeploy           bool     `json:"autodeploy" description:"Whether tracked runs are applied without confirmation"`
+	ProjectRoot          string   `json:"project_root" description:"Directory within the repository the stack runs in"`
+	Labels               []string `json:"labels" description:"Stack labels"`
+	EnvironmentVariables []string `json:"environment_variables" description:"Names of the environment variables set directly on the stack"`
+	HasBeforeInitHooks   bool     `json:"has_before_init_hooks" description:"Whether the stack defines before-init hooks"`
+	Dependencies         []string `json:"dependencies" description:"IDs of the stacks this stack depends on"`
+}
+
+// InputRun is a run in ValidationInput.
+type InputRun struct {
+	ID        string `json:"id" description:"Run ID"`
+	CreatedAt int64  `json:"created_at" description:"Creation time in nanoseconds since the Unix epoch"`
+	StackID   string `json:"stack_id" description:"ID of the stack the run belongs to"`
+	Type      string `json:"type" description:"Run type" jsonschema:"enum=PROPOSED|TRACKED|TASK|TESTING|DESTROY"`
+	Status    string `json:"status" description:"Run state, e.g. UNCONFIRMED or FINISHED"`
+}
+
+// InputAWSIntegration is an AWS integration in ValidationInput.
+type InputAWSIntegration struct {
+	Name string `json:"name" description:"Integration name"`
+}
+
+// InputAWSAttachment is an attachment of an AWS integration in
+// ValidationInput.
+type InputAWSAttachment struct {
+	Name       string `json:"name" description:"Name of the attached integration"`
+	AttachedTo string `json:"attached_to" description:"ID of the stack the integration is attached to"`
+}
+
+// InputPolicy is a policy in ValidationInput.
+type InputPolicy struct {
+	Name      string `json:"name" description:"Policy name"`
+	Type      string `json:"type" description:"Policy type, e.g. PLAN, APPROVAL or NOTIFICATION"`
+	SpaceSlug string `json:"space_slug" description:"Slug of the space the policy belongs to"`
+}
+
+// InputPolicyAttachment is an attachment of a policy in ValidationInput.
+type InputPolicyAttachment struct {
+	Name    string `json:"name" description:"Name of the attached policy"`
+	StackID string `json:"stack_id" description:"ID of the stack the policy is attached to"`
+}
+
+// InputContext is a context in ValidationInput.
+type InputContext struct {
+	Name                 string   `json:"name" description:"Context name"`
+	Labels               []string `json:"labels" description:"Context labels, including autoattach labels"`
+	EnvironmentVariables []string `json:"environment_variables" description:"Names of the environment variables the context sets"`
+	HasBeforeInitHooks   bool     `json:"has_before_init_hooks" description:"Whether the context defines before-init hooks"`
+}
+
+// InputContextAttachment is an attachment of a context in ValidationInput.
+type InputContextAttachment struct {
+	ContextName string `json:"context_name" description:"Name of the attached context"`
+	StackSlug   string `json:"stack_slug" description:"ID (slug) of the stack the context is attached to"`
+}
+
+// InputSpace is a space in ValidationInput.
+type InputSpace struct {
+	Name       string `json:"name" description:"Space name"`
+	Slug       string `json:"slug" description:"Space slug"`
+	ParentSlug string `json:"parent_slug" description:"Slug of the parent space; root for top-level spaces"`
+}
+
+// InputStackDependency is a dependency between stacks in ValidationInput.
+type InputStackDependency struct {
+	StackID   string `json:"stack_id" description:"ID of the dependent stack"`
+	DependsOn string `json:"depends_on" description:"ID of the stack it depends on"`
+}
+
+// InputStackDependencyReference passes an output of one stack as an input of
+// a stack that depends on it.
+type InputStackDependencyReference struct {
+	StackID    string `json:"stack_id" description:"ID of the dependent stack"`
+	DependsOn  string `json:"depends_on" description:"ID of the stack whose output is referenced"`
+	OutputName string `json:"output_name" description:"Name of the referenced output"`
+	InputName  string `json:"input_name" description:"Name of the environment variable the output is passed as"`
+}
+
+// generateSchema returns a function that generates the JSON Schema of the
+// Go type t, published as schema/<id>, on its first call and returns the
+// same result to every later one. Schemas are generated on first use rather
+// than at init, so that importers that never validate guides do not pay
+// for them. An error means this package's own types cannot be described;
+// Load reports it as a problem of the guides it was checking instead of
+// panicking.
+func generateSchema(t reflect.Type, id, title, description string) func() ([]byte, error) {
+	return sync.OnceValues(func() ([]byte, error) {
+		s, err := schemagen.Generate(t)
+		if err != nil {
+			return nil, err
+		}
+		s.Schema = schemagen.Draft
+		s.ID = id
+		s.Title = title
+		s.Description = description
+		return schemagen.Marshal(s)
+	})
+}
+
+// validationInputSchema generates the JSON Schema of ValidationInput.
+var validationInputSchema = generateSchema(reflect.TypeFor[ValidationInput](), "validation_input_schema.json",
+	"Spacelift Guide Step Validation Input", "Schema for the input document step validations are evaluated against")
+
+// validationInputSchemaDoc is the decoded JSON Schema of ValidationInput, in
+// the form the Rego type checker accepts.
+var validationInputSchemaDoc = sync.OnceValues(func() (any, error) {
+	data, err := validationInputSchema()
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+})
+
+// ValidationInputSchema returns the JSON Schema of ValidationInput, as
+// published in schema/validation_input_schema.json.
+func ValidationInputSchema() ([]byte, error) {
+	data, err := validationInputSchema()
+	return slices.Clone(data), err
+}
@@ -0,0 +1,34 @@
+package userguides_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+func TestSampleRegoInputMatchesModel(t *testing.T) {
+	schema := compileSchema(t, "schema/validation_input_schema.json")
+
+	data, err := os.ReadFile("rego_input/sample_rego_input.json")
+	if err != nil {
+		t.Fatalf("Failed to read sample input: %v", err)
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to parse sample input: %v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("Sample input does not match the schema:\n%v", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var input userguides.ValidationInput
+	if err := dec.Decode(&input); err != nil {
+		t.Errorf("Sample input does not decode into ValidationInput: %v", err)
+	}
+}
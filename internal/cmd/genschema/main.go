@@ -0,0 +1,30 @@
+// Command genschema writes the JSON Schemas generated from the library's Go
+// types to schema/. Run it with go generate from the repository root.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+func main() {
+	schemas := map[string]func() ([]byte, error){
+		"group_schema.json":            userguides.GroupSchema,
+		"chapter_schema.json":          userguides.ChapterSchema,
+		"guide_schema.json":            userguides.GuideSchema,
+		"validation_input_schema.json": userguides.ValidationInputSchema,
+	}
+
+	for name, schema := range schemas {
+		data, err := schema()
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join("schema", name), data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package policy parses and compiles the Rego policies that validate guide
+// steps. It is shared by the loader, which rejects broken policies, and the
+// validation package, which evaluates them.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+)
+
+const (
+	// Package is the Rego package every step validation must declare.
+	Package = "spacelift"
+	// Rule is the rule a step validation must define. The step is complete
+	// when it evaluates to true.
+	Rule = "valid"
+	// DenyRule is an optional set of strings explaining why a step is not
+	// complete yet.
+	DenyRule = "deny"
+)
+
+// Kind classifies a policy Error.
+type Kind int
+
+const (
+	KindSyntax Kind = iota
+	KindPackage
+	KindMissingRule
+	KindCompile
+	// KindType is a type error, such as a reference to an input field the
+	// input schema does not define.
+	KindType
+)
+
+// Error is a problem found in a policy. Line is relative to the start of
+// the policy source, starting at 1, and zero when unknown.
+type Error struct {
+	Kind    Kind
+	Line    int
+	Message string
+}
+
+func (e Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// Compile parses source as a step validation and compiles it on its own.
+// It returns every problem found, or the compiled module if there are none.
+//
+// If inputSchema is not nil, it is the decoded JSON Schema of the input
+// document and references to input are type-checked against it.
+func Compile(name, source string, inputSchema any) (*ast.Compiler, []Error) {
+	module, err := ast.ParseModuleWithOpts(name, source, ast.ParserOptions{RegoVersion: ast.RegoV1})
+	if err != nil {
+		return nil, astErrors(KindSyntax, err)
+	}
+
+	var errs []Error
+	if pkg := module.Package.Path.String(); pkg != "data."+Package {
+		errs = append(errs, Error{
+			Kind:    KindPackage,
+			Line:    module.Package.Location.Row,
+			Message: fmt.Sprintf("policy must declare package %s, found %s", Package, strings.TrimPrefix(pkg, "data.")),
+		})
+	}
+
+	hasRule := false
+	for _, rule := range module.Rules {
+		if rule.Head.Ref().String() == Rule {
+			hasRule = true
+			break
+		}
+	}
+	if !hasRule {
+		errs = append(errs, Error{
+			Kind:    KindMissingRule,
+			Message: fmt.Sprintf("policy must define a %q rule", Rule),
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	compiler := ast.NewCompiler()
+	if inputSchema != nil {
+		schemas := ast.NewSchemaSet()
+		schemas.Put(ast.SchemaRootRef, inputSchema)
+		compiler = compiler.WithSchemas(schemas)
+	}
+	compiler.Compile(map[string]*ast.Module{name: module})
+	if compiler.Failed() {
+		return nil, astErrors(KindCompile, compiler.Errors)
+	}
+
+	return compiler, nil
+}
+
+// astErrors converts the errors reported by the OPA parser or compiler.
+func astErrors(kind Kind, err error) []Error {
+	var astErrs ast.Errors
+	if !errors.As(err, &astErrs) {
+		return []Error{{Kind: kind, Message: err.Error()}}
+	}
+
+	errs := make([]Error, 0, len(astErrs))
+	for _, e := range astErrs {
+		line := 0
+		if e.Location != nil {
+			line = e.Location.Row
+		}
+		k := kind
+		if e.Code == ast.TypeErr {
+			k = KindType
+		}
+		errs = append(errs, Error{Kind: k, Line: line, Message: e.Message})
+	}
+	return errs
+}
@@ -0,0 +1,246 @@
+// Package schemagen generates JSON Schemas from Go types, so that the
+// schemas published in schema/ cannot drift from the types the library
+// decodes into.
+//
+// Properties are named after the field's json tag (or yaml tag when there is
+// no json tag) and listed in field order. A field is required unless it is
+// tagged omitempty or omitzero. Structs do not allow additional properties.
+//
+// Fields may be annotated with a description tag and a jsonschema tag
+// holding comma-separated constraints:
+//
+//	Difficulty string `yaml:"difficulty,omitempty" description:"How hard the guide is" jsonschema:"enum=easy|medium|hard"`
+//
+// Supported constraints are enum (values separated by |), minimum,
+// minItems, minLength, format and pattern, which therefore cannot contain a
+// comma. requires (property names separated by |) lists the properties that
+// must be present whenever the field is, as the enclosing object's
+// dependentRequired.
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Its fields marshal in the order a person would
+// write them.
+type Schema struct {
+	Schema               string              `json:"$schema,omitempty"`
+	ID                   string              `json:"$id,omitempty"`
+	Title                string              `json:"title,omitempty"`
+	Description          string              `json:"description,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Pattern              string              `json:"pattern,omitempty"`
+	Enum                 []string            `json:"enum,omitempty"`
+	Minimum              *int                `json:"minimum,omitempty"`
+	MinLength            *int                `json:"minLength,omitempty"`
+	MinItems             *int                `json:"minItems,omitempty"`
+	Items                *Schema             `json:"items,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+	AdditionalProperties any                 `json:"additionalProperties,omitempty"`
+	Properties           Properties          `json:"properties,omitempty"`
+}
+
+// Property is a named entry of Properties.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an object schema, in declaration order.
+type Properties []Property
+
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(prop.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Generate returns the schema of t, which must be a struct type.
+func Generate(t reflect.Type) (*Schema, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schemagen: %s is not a struct", t)
+	}
+	return generate(t)
+}
+
+// Marshal renders s as indented JSON followed by a newline, the format of
+// the files in schema/.
+func Marshal(s *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func generate(t reflect.Type) (*Schema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schemagen: map key of %s is not a string", t)
+		}
+		values, err := generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return generateStruct(t)
+	default:
+		return nil, fmt.Errorf("schemagen: unsupported type %s", t)
+	}
+}
+
+func generateStruct(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", AdditionalProperties: false}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := fieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		prop, err := generate(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		prop.Description = field.Tag.Get("description")
+		if err := applyConstraints(s, name, prop, field.Tag.Get("jsonschema")); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties = append(s.Properties, Property{Name: name, Schema: prop})
+	}
+
+	return s, nil
+}
+
+// fieldName returns the property name of a struct field and whether it is
+// optional.
+func fieldName(field reflect.StructField) (name string, omitempty bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		tag, ok = field.Tag.Lookup("yaml")
+	}
+	if !ok {
+		return field.Name, false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// applyConstraints applies the jsonschema tag of the field name of the
+// object parent to s, the schema of the field. For arrays, every constraint
+// but minItems and requires applies to the items.
+func applyConstraints(parent *Schema, name string, s *Schema, tag string) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, constraint := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(constraint, "=")
+		if !ok {
+			return fmt.Errorf("schemagen: malformed constraint %q", constraint)
+		}
+
+		target := s
+		if s.Items != nil && key != "minItems" && key != "requires" {
+			target = s.Items
+		}
+
+		switch key {
+		case "enum":
+			target.Enum = strings.Split(value, "|")
+		case "format":
+			target.Format = value
+		case "pattern":
+			target.Pattern = value
+		case "requires":
+			if parent.DependentRequired == nil {
+				parent.DependentRequired = make(map[string][]string)
+			}
+			parent.DependentRequired[name] = strings.Split(value, "|")
+		case "minimum", "minItems", "minLength":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("schemagen: %s must be an integer, got %q", key, value)
+			}
+			switch key {
+			case "minimum":
+				target.Minimum = &n
+			case "minItems":
+				target.MinItems = &n
+			case "minLength":
+				target.MinLength = &n
+			}
+		default:
+			return fmt.Errorf("schemagen: unknown constraint %q", key)
+		}
+	}
+
+	return nil
+}
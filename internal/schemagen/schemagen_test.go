@@ -0,0 +1,76 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	Name string `json:"name" description:"Item name"`
+}
+
+type testDoc struct {
+	Title    string            `yaml:"title" jsonschema:"minLength=1"`
+	Slug     string            `yaml:"slug,omitempty" jsonschema:"pattern=^[a-z]+$,requires=title|level"`
+	Level    string            `yaml:"level,omitempty" jsonschema:"enum=easy|hard"`
+	Minutes  int               `yaml:"minutes,omitempty" jsonschema:"minimum=1"`
+	Labels   []string          `yaml:"labels,omitempty" jsonschema:"minItems=1,minLength=1"`
+	Items    []testItem        `yaml:"items"`
+	Values   map[string]string `yaml:"values,omitempty"`
+	Ignored  string            `yaml:"-"`
+	internal string
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := Generate(reflect.TypeFor[testDoc]())
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	want := `{"type":"object","required":["title","items"],"dependentRequired":{"slug":["title","level"]},"additionalProperties":false,"properties":{` +
+		`"title":{"type":"string","minLength":1},` +
+		`"slug":{"type":"string","pattern":"^[a-z]+$"},` +
+		`"level":{"type":"string","enum":["easy","hard"]},` +
+		`"minutes":{"type":"integer","minimum":1},` +
+		`"labels":{"type":"array","minItems":1,"items":{"type":"string","minLength":1}},` +
+		`"items":{"type":"array","items":{"type":"object","required":["name"],"additionalProperties":false,"properties":{"name":{"description":"Item name","type":"string"}}}},` +
+		`"values":{"type":"object","additionalProperties":{"type":"string"}}}}`
+	if got := string(data); got != want {
+		t.Errorf("Generate =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	type badConstraint struct {
+		Name string `json:"name" jsonschema:"maxLength=1"`
+	}
+	type badMap struct {
+		Values map[int]string `json:"values"`
+	}
+
+	tests := []struct {
+		name   string
+		typ    reflect.Type
+		errMsg string
+	}{
+		{"not a struct", reflect.TypeFor[string](), "not a struct"},
+		{"unknown constraint", reflect.TypeFor[badConstraint](), `unknown constraint "maxLength"`},
+		{"map key", reflect.TypeFor[badMap](), "map key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
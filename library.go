@@ -2,12 +2,17 @@ package userguides
 
 import (
 	"embed"
-	"fmt"
+	"errors"
 	"io/fs"
 	"net/url"
 	"path"
+	"reflect"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,10 +20,18 @@ import (
 //go:embed guides
 var guidesFS embed.FS
 
+// Library is the root of the guide hierarchy. Libraries returned by Load
+// carry indexes for the lookup methods; they are built once and assume the
+// Groups tree is not modified afterwards.
 type Library struct {
 	Groups []Group
+
+	index    *libraryIndex
+	warnings []Problem
 }
 
+// Group is the top level of the hierarchy. Groups in a Library are sorted by
+// Ordering, with Slug breaking ties.
 type Group struct {
 	Slug        string
 	Name        string
@@ -26,8 +39,12 @@ type Group struct {
 	SkillLevel  string
 	Ordering    int
 	Chapters    []Chapter
+
+	source string
 }
 
+// Chapter belongs to a Group. Chapters are sorted by Ordering, which is
+// unique within the group.
 type Chapter struct {
 	Slug        string
 	Name        string
@@ -35,24 +52,43 @@ type Chapter struct {
 	Ordering    int
 	Variables   []GuideVariable
 	Guides      []Guide
+
+	source string
 }
 
+// Guide belongs to a Chapter. Guides are sorted by Ordering, which is unique
+// within the chapter.
 type Guide struct {
 	Slug                   string
 	Ordering               int
+	ReleaseState           ReleaseState
 	PrerequisiteGuideSlugs []string
 	Metadata               GuideMetadata
 	Steps                  []GuideStep
 	Completion             GuideCompletion
+
+	source string
 }
 
+// ReleaseState says who a guide is served to. Guides without a
+// releaseState are published.
+type ReleaseState string
+
+const (
+	// ReleaseStatePublished guides are served to every user.
+	ReleaseStatePublished ReleaseState = "published"
+	// ReleaseStateTesting guides are only served by environments that
+	// include guides under test, see Library.Filter.
+	ReleaseStateTesting ReleaseState = "testing"
+)
+
 type GuideMetadata struct {
-	Title             string   `yaml:"title"`
-	Description       string   `yaml:"description"`
-	Labels            []string `yaml:"labels"`
-	Difficulty        string   `yaml:"difficulty"`
-	MinutesToComplete int      `yaml:"minutesToComplete"`
-	Prerequisites     []string `yaml:"prerequisites"`
+	Title             string   `yaml:"title" description:"Display title of the guide"`
+	Description       string   `yaml:"description" description:"Short summary of what the guide covers"`
+	Labels            []string `yaml:"labels" description:"Tags for categorization and search" jsonschema:"minItems=1"`
+	Difficulty        string   `yaml:"difficulty" description:"Difficulty level of the guide" jsonschema:"enum=easy|medium|hard"`
+	MinutesToComplete int      `yaml:"minutesToComplete" description:"Estimated time to complete the guide in minutes" jsonschema:"minimum=1"`
+	Prerequisites     []string `yaml:"prerequisites" description:"Human-readable descriptions of what the user needs before starting"`
 }
 
 type VariableResourceType string
@@ -66,47 +102,128 @@ const (
 )
 
 type GuideVariable struct {
-	Name         string               `yaml:"name"`
-	Description  string               `yaml:"description"`
-	ResourceType VariableResourceType `yaml:"resourceType"`
+	Name         string               `yaml:"name" description:"Variable name, referenced in guides as ${name}"`
+	Description  string               `yaml:"description" description:"Human-readable description of the variable"`
+	ResourceType VariableResourceType `yaml:"resourceType" description:"The Spacelift resource type this variable represents" jsonschema:"enum=stack|policy|aws_integration|context|space"`
 }
 
+// GuideStep is one step of a Guide. Steps are sorted by Order, which runs
+// from 1 to the number of steps.
 type GuideStep struct {
-	Order          int        `yaml:"order"`
-	Title          string     `yaml:"title"`
-	Instruction    string     `yaml:"instruction"`
-	Hint           string     `yaml:"hint"`
-	ValidationHint string     `yaml:"validationHint"`
-	Validation     string     `yaml:"validation"`
-	Docs           []GuideDoc `yaml:"docs"`
+	Order          int        `yaml:"order" description:"Step sequence number" jsonschema:"minimum=1"`
+	Title          string     `yaml:"title" description:"Short title for the step"`
+	Instruction    string     `yaml:"instruction" description:"Detailed instructions for the user. Supports markdown and template variables (${variable_name})."`
+	Hint           string     `yaml:"hint,omitempty" description:"Additional context or tips to help the user understand the step"`
+	ValidationHint string     `yaml:"validationHint,omitempty" description:"Guidance shown to the user about what must be true before proceeding"`
+	Validation     string     `yaml:"validation,omitempty" description:"OPA/Rego policy that validates the step was completed correctly. Must define a 'valid' rule in the 'spacelift' package." jsonschema:"requires=validationHint"`
+	Docs           []GuideDoc `yaml:"docs,omitempty" description:"Links to relevant documentation"`
 }
 
 type GuideDoc struct {
-	Title string `yaml:"title"`
-	URL   string `yaml:"url"`
+	Title string `yaml:"title" description:"Display title for the documentation link"`
+	URL   string `yaml:"url" description:"URL to the documentation page" jsonschema:"format=uri,pattern=^https?://"`
 }
 
 type GuideCompletion struct {
-	SuccessMessage      string   `yaml:"successMessage"`
-	RecommendedGuideIDs []string `yaml:"recommendedGuideIds"`
+	SuccessMessage      string   `yaml:"successMessage" description:"Message shown to the user upon completing the guide"`
+	RecommendedGuideIDs []string `yaml:"recommendedGuideIds" description:"Slugs of guides recommended as next steps"`
 }
 
+var loadEmbedded = sync.OnceValues(func() (*Library, error) {
+	return Load(guidesFS)
+})
+
+// Guides returns the library embedded in this module. The content is parsed
+// and validated on the first call only; every caller then shares the same
+// *Library, which must be treated as read-only.
 func Guides() (*Library, error) {
-	lib, err := parse(guidesFS)
+	return loadEmbedded()
+}
+
+// MustGuides is like Guides but panics if the embedded library is invalid.
+// It is meant for package initialization, where failing fast is preferable
+// to serving without guides.
+func MustGuides() *Library {
+	lib, err := Guides()
 	if err != nil {
 		panic("userguides: " + err.Error())
 	}
-	return lib, nil
+	return lib
+}
+
+// DefaultRoot is the directory, relative to the root of the loaded file
+// system, that holds the group directories.
+const DefaultRoot = "guides"
+
+type loadOptions struct {
+	root            string
+	crossReferences bool
+}
+
+// LoadOption configures how Load reads and validates a library.
+type LoadOption func(*loadOptions)
+
+// WithRoot sets the directory within the file system that holds the group
+// directories. It defaults to DefaultRoot; use "." when the file system is
+// already rooted at the guides directory.
+func WithRoot(dir string) LoadOption {
+	return func(o *loadOptions) {
+		o.root = dir
+	}
+}
+
+// WithCrossReferenceChecks enables or disables the checks that span more
+// than one file, such as slug uniqueness and the integrity of
+// recommendedGuideIds and prerequisiteGuideSlugs.
+// They are enabled by default; disabling them is useful when loading a
+// partial overlay whose references resolve against another library.
+func WithCrossReferenceChecks(enabled bool) LoadOption {
+	return func(o *loadOptions) {
+		o.crossReferences = enabled
+	}
+}
+
+// Load parses and validates the library stored in fsys. By default the
+// group directories are expected under DefaultRoot.
+//
+// Every file is checked even after a problem has been found. If any check
+// fails, the returned error is a *ValidationReport listing all of them.
+func Load(fsys fs.FS, opts ...LoadOption) (*Library, error) {
+	o := loadOptions{
+		root:            DefaultRoot,
+		crossReferences: true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return parse(fsys, o)
+}
+
+// loader accumulates the problems found while parsing a library, together
+// with the YAML documents needed to locate them.
+type loader struct {
+	fsys   fs.FS
+	opts   loadOptions
+	report ValidationReport
+	docs   map[string]*yaml.Node
 }
 
-func parse(f fs.FS) (*Library, error) {
+func parse(f fs.FS, opts loadOptions) (*Library, error) {
+	l := &loader{
+		fsys: f,
+		opts: opts,
+		docs: make(map[string]*yaml.Node),
+	}
+
 	lib := &Library{
 		Groups: []Group{},
 	}
 
-	groupDirs, err := fs.ReadDir(f, "guides")
+	groupDirs, err := fs.ReadDir(f, opts.root)
 	if err != nil {
-		return nil, fmt.Errorf("read guides directory: %w", err)
+		l.fail(opts.root, nil, CodeFileUnreadable, "read guides directory: %v", err)
+		return nil, &l.report
 	}
 
 	for _, groupDir := range groupDirs {
@@ -114,28 +231,115 @@ func parse(f fs.FS) (*Library, error) {
 			continue
 		}
 
-		group, err := parseGroup(f, groupDir.Name())
-		if err != nil {
-			return nil, fmt.Errorf("parse group %s: %w", groupDir.Name(), err)
+		lib.Groups = append(lib.Groups, l.parseGroup(groupDir.Name()))
+	}
+
+	sort.SliceStable(lib.Groups, func(i, j int) bool {
+		a, b := lib.Groups[i], lib.Groups[j]
+		if a.Ordering != b.Ordering {
+			return a.Ordering < b.Ordering
 		}
+		return a.Slug < b.Slug
+	})
 
-		lib.Groups = append(lib.Groups, group)
+	if opts.crossReferences {
+		l.validateLibrary(lib)
 	}
 
-	if err := validateLibrary(lib); err != nil {
+	if err := l.report.Err(); err != nil {
 		return nil, err
 	}
 
+	lib.index = newLibraryIndex(lib)
+	lib.warnings = l.report.Problems
+
 	return lib, nil
 }
 
-func validateLibrary(lib *Library) error {
+// Warnings returns the non-fatal problems found while loading the library,
+// such as chapter variables that no guide uses.
+func (l *Library) Warnings() []Problem {
+	return l.warnings
+}
+
+// fail records an error-severity problem at field within the file name.
+func (l *loader) fail(name string, field []any, code RuleCode, format string, args ...any) {
+	var r ValidationReport
+	r.errorf(code, field, format, args...)
+	l.report.attach(&r, name, l.docs[name])
+}
+
+// check runs validate and records its problems against the file name.
+func (l *loader) check(name string, validate func(*ValidationReport)) {
+	var r ValidationReport
+	validate(&r)
+	l.report.attach(&r, name, l.docs[name])
+}
+
+var yamlLinePrefix = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// decode reads the YAML file name into v. Unreadable files and malformed
+// YAML are recorded as problems, in which case decode returns false and v
+// must not be validated. Keys that v has no field for are recorded as
+// problems too, but do not stop v from being validated.
+func (l *loader) decode(name string, v any) bool {
+	data, err := fs.ReadFile(l.fsys, name)
+	if err != nil {
+		l.fail(name, nil, CodeFileUnreadable, "read %s: %v", path.Base(name), err)
+		return false
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		l.yamlError(name, CodeYAMLSyntax, err.Error())
+		return false
+	}
+	l.docs[name] = &doc
+
+	if len(doc.Content) == 0 {
+		return true
+	}
+
+	if err := doc.Decode(v); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			l.yamlError(name, CodeYAMLSyntax, err.Error())
+			return false
+		}
+		for _, msg := range typeErr.Errors {
+			l.yamlError(name, CodeYAMLType, msg)
+		}
+		return false
+	}
+	l.checkKnownFields(name, reflect.TypeOf(v))
+
+	return true
+}
+
+// yamlError records a yaml.v3 error message, lifting the line number it
+// embeds into the problem position.
+func (l *loader) yamlError(name string, code RuleCode, msg string) {
+	p := Problem{
+		Path:     name,
+		Severity: SeverityError,
+		Code:     code,
+		Message:  strings.TrimPrefix(msg, "yaml: "),
+	}
+	if m := yamlLinePrefix.FindStringSubmatch(msg); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Message = msg[len(m[0]):]
+	}
+	l.report.Problems = append(l.report.Problems, p)
+}
+
+func (l *loader) validateLibrary(lib *Library) {
 	groupSlugs := make(map[string]bool)
 	guideSlugs := make(map[string]bool)
+	guidesBySlug := make(map[string]Guide)
 
 	for _, group := range lib.Groups {
 		if groupSlugs[group.Slug] {
-			return fmt.Errorf("duplicate group slug: %s", group.Slug)
+			l.fail(group.source, nil, CodeGroupSlugDuplicate, "duplicate group slug: %s", group.Slug)
 		}
 		groupSlugs[group.Slug] = true
 
@@ -143,24 +347,26 @@ func validateLibrary(lib *Library) error {
 		chapterOrderings := make(map[int]bool)
 		for _, chapter := range group.Chapters {
 			if chapterSlugs[chapter.Slug] {
-				return fmt.Errorf("duplicate chapter slug %s in group %s", chapter.Slug, group.Slug)
+				l.fail(chapter.source, nil, CodeChapterSlugDuplicate, "duplicate chapter slug %s in group %s", chapter.Slug, group.Slug)
 			}
 			chapterSlugs[chapter.Slug] = true
 
 			if chapterOrderings[chapter.Ordering] {
-				return fmt.Errorf("duplicate chapter ordering %d in group %s", chapter.Ordering, group.Slug)
+				l.fail(chapter.source, []any{"ordering"}, CodeChapterOrderingDuplicate, "duplicate chapter ordering %d in group %s", chapter.Ordering, group.Slug)
 			}
 			chapterOrderings[chapter.Ordering] = true
 
 			guideOrderings := make(map[int]bool)
 			for _, guide := range chapter.Guides {
 				if guideSlugs[guide.Slug] {
-					return fmt.Errorf("duplicate guide slug %s in chapter %s/%s", guide.Slug, group.Slug, chapter.Slug)
+					l.fail(guide.source, []any{"slug"}, CodeGuideSlugDuplicate, "duplicate guide slug %s in chapter %s/%s", guide.Slug, group.Slug, chapter.Slug)
+				} else {
+					guidesBySlug[guide.Slug] = guide
 				}
 				guideSlugs[guide.Slug] = true
 
 				if guideOrderings[guide.Ordering] {
-					return fmt.Errorf("duplicate guide ordering %d in chapter %s/%s", guide.Ordering, group.Slug, chapter.Slug)
+					l.fail(guide.source, []any{"ordering"}, CodeGuideOrderingDuplicate, "duplicate guide ordering %d in chapter %s/%s", guide.Ordering, group.Slug, chapter.Slug)
 				}
 				guideOrderings[guide.Ordering] = true
 			}
@@ -170,38 +376,43 @@ func validateLibrary(lib *Library) error {
 	for _, group := range lib.Groups {
 		for _, chapter := range group.Chapters {
 			for _, guide := range chapter.Guides {
-				for _, recommendedID := range guide.Completion.RecommendedGuideIDs {
+				guidePath := group.Slug + "/" + chapter.Slug + "/" + guide.Slug
+				for i, recommendedID := range guide.Completion.RecommendedGuideIDs {
+					field := []any{"completion", "recommendedGuideIds", i}
 					if !guideSlugs[recommendedID] {
-						guidePath := group.Slug + "/" + chapter.Slug + "/" + guide.Slug
-						return fmt.Errorf("guide %s references non-existent guide in recommendedGuideIds: %s", guidePath, recommendedID)
+						l.fail(guide.source, field, CodeRecommendedGuideNotFound, "guide %s references non-existent guide in recommendedGuideIds: %s", guidePath, recommendedID)
+					} else if guide.published() && !guidesBySlug[recommendedID].published() {
+						l.fail(guide.source, field, CodeReferenceNotPublished, "published guide %s recommends guide %s, which is not published", guidePath, recommendedID)
+					}
+				}
+				for i, prereq := range guide.PrerequisiteGuideSlugs {
+					field := []any{"prerequisiteGuideSlugs", i}
+					if prereq == guide.Slug {
+						l.fail(guide.source, field, CodePrerequisiteSelf, "guide %s lists itself in prerequisiteGuideSlugs", guidePath)
+					} else if !guideSlugs[prereq] {
+						l.fail(guide.source, field, CodePrerequisiteNotFound, "guide %s references non-existent guide in prerequisiteGuideSlugs: %s", guidePath, prereq)
+					} else if guide.published() && !guidesBySlug[prereq].published() {
+						l.fail(guide.source, field, CodeReferenceNotPublished, "published guide %s requires guide %s, which is not published", guidePath, prereq)
 					}
 				}
 			}
 		}
 	}
 
-	return nil
+	for _, cycle := range prerequisiteCycles(lib) {
+		guide := guidesBySlug[cycle[0]]
+		field := []any{"prerequisiteGuideSlugs", slices.Index(guide.PrerequisiteGuideSlugs, cycle[1])}
+		l.fail(guide.source, field, CodePrerequisiteCycle, "guide %s has cyclic prerequisites: %s", guide.Slug, strings.Join(cycle, " -> "))
+	}
 }
 
-func parseGroup(f fs.FS, groupSlug string) (Group, error) {
-	groupPath := path.Join("guides", groupSlug)
+func (l *loader) parseGroup(groupSlug string) Group {
+	groupPath := path.Join(l.opts.root, groupSlug)
 	groupYAMLPath := path.Join(groupPath, "group.yaml")
 
-	data, err := fs.ReadFile(f, groupYAMLPath)
-	if err != nil {
-		return Group{}, fmt.Errorf("read group.yaml: %w", err)
-	}
+	var groupMeta groupDocument
 
-	var groupMeta struct {
-		Name        string `yaml:"name"`
-		Description string `yaml:"description"`
-		SkillLevel  string `yaml:"skillLevel"`
-		Ordering    int    `yaml:"ordering"`
-	}
-
-	if err := yaml.Unmarshal(data, &groupMeta); err != nil {
-		return Group{}, fmt.Errorf("parse group.yaml: %w", err)
-	}
+	ok := l.decode(groupYAMLPath, &groupMeta)
 
 	group := Group{
 		Slug:        groupSlug,
@@ -210,15 +421,18 @@ func parseGroup(f fs.FS, groupSlug string) (Group, error) {
 		SkillLevel:  groupMeta.SkillLevel,
 		Ordering:    groupMeta.Ordering,
 		Chapters:    []Chapter{},
+		source:      groupYAMLPath,
 	}
 
-	if err := group.Validate(); err != nil {
-		return Group{}, err
+	if ok {
+		l.check(groupYAMLPath, group.validate)
+		l.checkSchema(groupYAMLPath, groupSchema)
 	}
 
-	chapterDirs, err := fs.ReadDir(f, groupPath)
+	chapterDirs, err := fs.ReadDir(l.fsys, groupPath)
 	if err != nil {
-		return Group{}, fmt.Errorf("read group directory: %w", err)
+		l.fail(groupPath, nil, CodeFileUnreadable, "read group directory: %v", err)
+		return group
 	}
 
 	for _, chapterDir := range chapterDirs {
@@ -226,36 +440,27 @@ func parseGroup(f fs.FS, groupSlug string) (Group, error) {
 			continue
 		}
 
-		chapter, err := parseChapter(f, groupSlug, chapterDir.Name())
-		if err != nil {
-			return Group{}, fmt.Errorf("parse chapter %s: %w", chapterDir.Name(), err)
-		}
-
-		group.Chapters = append(group.Chapters, chapter)
+		group.Chapters = append(group.Chapters, l.parseChapter(groupSlug, chapterDir.Name()))
 	}
 
-	return group, nil
+	sort.SliceStable(group.Chapters, func(i, j int) bool {
+		a, b := group.Chapters[i], group.Chapters[j]
+		if a.Ordering != b.Ordering {
+			return a.Ordering < b.Ordering
+		}
+		return a.Slug < b.Slug
+	})
+
+	return group
 }
 
-func parseChapter(f fs.FS, groupSlug, chapterSlug string) (Chapter, error) {
-	chapterPath := path.Join("guides", groupSlug, chapterSlug)
+func (l *loader) parseChapter(groupSlug, chapterSlug string) Chapter {
+	chapterPath := path.Join(l.opts.root, groupSlug, chapterSlug)
 	chapterYAMLPath := path.Join(chapterPath, "chapter.yaml")
 
-	data, err := fs.ReadFile(f, chapterYAMLPath)
-	if err != nil {
-		return Chapter{}, fmt.Errorf("read chapter.yaml: %w", err)
-	}
-
-	var chapterMeta struct {
-		Name        string          `yaml:"name"`
-		Description string          `yaml:"description"`
-		Ordering    int             `yaml:"ordering"`
-		Variables   []GuideVariable `yaml:"variables"`
-	}
+	var chapterMeta chapterDocument
 
-	if err := yaml.Unmarshal(data, &chapterMeta); err != nil {
-		return Chapter{}, fmt.Errorf("parse chapter.yaml: %w", err)
-	}
+	ok := l.decode(chapterYAMLPath, &chapterMeta)
 
 	chapter := Chapter{
 		Slug:        chapterSlug,
@@ -264,15 +469,22 @@ func parseChapter(f fs.FS, groupSlug, chapterSlug string) (Chapter, error) {
 		Ordering:    chapterMeta.Ordering,
 		Variables:   chapterMeta.Variables,
 		Guides:      []Guide{},
+		source:      chapterYAMLPath,
 	}
 
-	if err := chapter.Validate(); err != nil {
-		return Chapter{}, err
+	// Without valid metadata every placeholder would look undeclared.
+	variables := &chapter
+	if ok {
+		l.check(chapterYAMLPath, chapter.validate)
+		l.checkSchema(chapterYAMLPath, chapterSchema)
+	} else {
+		variables = nil
 	}
 
-	entries, err := fs.ReadDir(f, chapterPath)
+	entries, err := fs.ReadDir(l.fsys, chapterPath)
 	if err != nil {
-		return Chapter{}, fmt.Errorf("read chapter directory: %w", err)
+		l.fail(chapterPath, nil, CodeFileUnreadable, "read chapter directory: %v", err)
+		return chapter
 	}
 
 	for _, entry := range entries {
@@ -280,64 +492,87 @@ func parseChapter(f fs.FS, groupSlug, chapterSlug string) (Chapter, error) {
 			continue
 		}
 
-		guide, err := parseGuide(f, groupSlug, chapterSlug, entry.Name())
-		if err != nil {
-			return Chapter{}, fmt.Errorf("parse guide %s: %w", entry.Name(), err)
+		if guide, ok := l.parseGuide(path.Join(chapterPath, entry.Name()), variables); ok {
+			chapter.Guides = append(chapter.Guides, guide)
 		}
+	}
 
-		chapter.Guides = append(chapter.Guides, guide)
+	if variables != nil {
+		l.check(chapterYAMLPath, chapter.validateVariableUsage)
 	}
 
-	return chapter, nil
-}
+	sort.SliceStable(chapter.Guides, func(i, j int) bool {
+		a, b := chapter.Guides[i], chapter.Guides[j]
+		if a.Ordering != b.Ordering {
+			return a.Ordering < b.Ordering
+		}
+		return a.Slug < b.Slug
+	})
 
-func parseGuide(f fs.FS, groupSlug, chapterSlug, guideFile string) (Guide, error) {
-	guidePath := path.Join("guides", groupSlug, chapterSlug, guideFile)
+	return chapter
+}
 
-	data, err := fs.ReadFile(f, guidePath)
-	if err != nil {
-		return Guide{}, fmt.Errorf("read guide file: %w", err)
-	}
+// parseGuide parses the guide at guidePath. When chapter is not nil, the
+// guide's placeholders are checked against the variables it declares.
+func (l *loader) parseGuide(guidePath string, chapter *Chapter) (Guide, bool) {
+	var guideMeta guideDocument
 
-	var guideMeta struct {
-		Slug                   string          `yaml:"slug"`
-		Ordering               int             `yaml:"ordering"`
-		PrerequisiteGuideSlugs []string        `yaml:"prerequisiteGuideSlugs"`
-		Metadata               GuideMetadata   `yaml:"metadata"`
-		Steps                  []GuideStep     `yaml:"steps"`
-		Completion             GuideCompletion `yaml:"completion"`
+	if !l.decode(guidePath, &guideMeta) {
+		return Guide{}, false
 	}
 
-	if err := yaml.Unmarshal(data, &guideMeta); err != nil {
-		return Guide{}, fmt.Errorf("parse guide YAML: %w", err)
+	if guideMeta.Slug == "" {
+		l.fail(guidePath, []any{"slug"}, CodeGuideSlugRequired, "guide %s: slug cannot be empty", path.Base(guidePath))
 	}
 
-	if guideMeta.Slug == "" {
-		return Guide{}, fmt.Errorf("guide %s: slug cannot be empty", guideFile)
+	if guideMeta.ReleaseState == "" {
+		guideMeta.ReleaseState = ReleaseStatePublished
 	}
 
 	guide := Guide{
 		Slug:                   guideMeta.Slug,
 		Ordering:               guideMeta.Ordering,
+		ReleaseState:           guideMeta.ReleaseState,
 		PrerequisiteGuideSlugs: guideMeta.PrerequisiteGuideSlugs,
 		Metadata:               guideMeta.Metadata,
 		Steps:                  guideMeta.Steps,
 		Completion:             guideMeta.Completion,
+		source:                 guidePath,
 	}
 
-	if err := guide.Validate(); err != nil {
-		return Guide{}, err
+	// Validate before sorting so that problems point at the steps as they
+	// appear in the file.
+	l.check(guidePath, guide.validate)
+	l.check(guidePath, guide.validateStepPolicies)
+	if chapter != nil {
+		l.check(guidePath, func(r *ValidationReport) {
+			chapter.validateGuideVariables(guide, r)
+		})
 	}
+	l.checkSchema(guidePath, guideSchema)
+
+	sort.SliceStable(guide.Steps, func(i, j int) bool {
+		return guide.Steps[i].Order < guide.Steps[j].Order
+	})
 
-	return guide, nil
+	return guide, true
 }
 
+// Validate checks the group on its own. The returned error, if any, is a
+// *ValidationReport.
 func (g Group) Validate() error {
+	var r ValidationReport
+	g.validate(&r)
+	return r.Err()
+}
+
+func (g Group) validate(r *ValidationReport) {
 	if g.Name == "" {
-		return fmt.Errorf("group %s: name cannot be empty", g.Slug)
+		r.errorf(CodeGroupNameRequired, []any{"name"}, "group %s: name cannot be empty", g.Slug)
 	}
 	if g.SkillLevel == "" {
-		return fmt.Errorf("group %s: skill level cannot be empty", g.Slug)
+		r.errorf(CodeGroupSkillLevelRequired, []any{"skillLevel"}, "group %s: skill level cannot be empty", g.Slug)
+		return
 	}
 	validSkillLevels := map[string]bool{
 		"BEGINNER":  true,
@@ -346,14 +581,21 @@ func (g Group) Validate() error {
 		"GUARDIAN":  true,
 	}
 	if !validSkillLevels[g.SkillLevel] {
-		return fmt.Errorf("group %s: invalid skill level %q (must be BEGINNER, ENABLER, COMMANDER, or GUARDIAN)", g.Slug, g.SkillLevel)
+		r.errorf(CodeGroupSkillLevelInvalid, []any{"skillLevel"}, "group %s: invalid skill level %q (must be BEGINNER, ENABLER, COMMANDER, or GUARDIAN)", g.Slug, g.SkillLevel)
 	}
-	return nil
 }
 
+// Validate checks the chapter metadata on its own, without its guides. The
+// returned error, if any, is a *ValidationReport.
 func (c Chapter) Validate() error {
+	var r ValidationReport
+	c.validate(&r)
+	return r.Err()
+}
+
+func (c Chapter) validate(r *ValidationReport) {
 	if c.Name == "" {
-		return fmt.Errorf("chapter %s: name cannot be empty", c.Slug)
+		r.errorf(CodeChapterNameRequired, []any{"name"}, "chapter %s: name cannot be empty", c.Slug)
 	}
 	validResourceTypes := map[VariableResourceType]bool{
 		VariableResourceTypeStack:          true,
@@ -362,88 +604,114 @@ func (c Chapter) Validate() error {
 		VariableResourceTypeContext:        true,
 		VariableResourceTypeSpace:          true,
 	}
-	for _, v := range c.Variables {
+	for i, v := range c.Variables {
+		field := []any{"variables", i, "resourceType"}
 		if v.ResourceType == "" {
-			return fmt.Errorf("chapter %s: variable %q is missing resourceType", c.Slug, v.Name)
-		}
-		if !validResourceTypes[v.ResourceType] {
-			return fmt.Errorf("chapter %s: variable %q has invalid resourceType %q", c.Slug, v.Name, v.ResourceType)
+			r.errorf(CodeVariableResourceTypeRequired, field, "chapter %s: variable %q is missing resourceType", c.Slug, v.Name)
+		} else if !validResourceTypes[v.ResourceType] {
+			r.errorf(CodeVariableResourceTypeInvalid, field, "chapter %s: variable %q has invalid resourceType %q", c.Slug, v.Name, v.ResourceType)
 		}
 	}
-	return nil
 }
 
+// Validate checks the guide on its own, without resolving references to
+// other guides. The returned error, if any, is a *ValidationReport.
 func (g Guide) Validate() error {
+	var r ValidationReport
+	g.validate(&r)
+	return r.Err()
+}
+
+func (g Guide) validate(r *ValidationReport) {
 	if g.Metadata.Title == "" {
-		return fmt.Errorf("guide %s: title cannot be empty", g.Slug)
+		r.errorf(CodeGuideTitleRequired, []any{"metadata", "title"}, "guide %s: title cannot be empty", g.Slug)
 	}
 	if len(g.Steps) == 0 {
-		return fmt.Errorf("guide %s: must have at least one step", g.Slug)
+		r.errorf(CodeGuideStepsRequired, []any{"steps"}, "guide %s: must have at least one step", g.Slug)
 	}
 
-	if g.Metadata.Difficulty != "" {
-		validDifficulties := map[string]bool{
-			"easy":   true,
-			"medium": true,
-			"hard":   true,
-		}
-		if !validDifficulties[g.Metadata.Difficulty] {
-			return fmt.Errorf("guide %s: invalid difficulty %q (must be easy, medium, or hard)", g.Slug, g.Metadata.Difficulty)
-		}
+	validDifficulties := map[string]bool{
+		"easy":   true,
+		"medium": true,
+		"hard":   true,
+	}
+	if g.Metadata.Difficulty == "" {
+		r.errorf(CodeGuideDifficultyRequired, []any{"metadata", "difficulty"}, "guide %s: difficulty cannot be empty", g.Slug)
+	} else if !validDifficulties[g.Metadata.Difficulty] {
+		r.errorf(CodeGuideDifficultyInvalid, []any{"metadata", "difficulty"}, "guide %s: invalid difficulty %q (must be easy, medium, or hard)", g.Slug, g.Metadata.Difficulty)
+	}
+
+	switch g.ReleaseState {
+	case "", ReleaseStatePublished, ReleaseStateTesting:
+	default:
+		r.errorf(CodeGuideReleaseStateInvalid, []any{"releaseState"}, "guide %s: invalid releaseState %q (must be testing or published)", g.Slug, g.ReleaseState)
 	}
 
+	if len(g.Metadata.Labels) == 0 {
+		r.errorf(CodeGuideLabelsRequired, []any{"metadata", "labels"}, "guide %s: must have at least one label", g.Slug)
+	}
 	for i, label := range g.Metadata.Labels {
 		if strings.TrimSpace(label) == "" {
-			return fmt.Errorf("guide %s: label at index %d is empty", g.Slug, i)
+			r.errorf(CodeGuideLabelEmpty, []any{"metadata", "labels", i}, "guide %s: label at index %d is empty", g.Slug, i)
 		}
 	}
 
 	var orders []int
 	stepOrders := make(map[int]bool)
-	for _, step := range g.Steps {
+	stepIndexes := make(map[int]int)
+	orderingValid := true
+	for i, step := range g.Steps {
 		if step.Order <= 0 {
-			return fmt.Errorf("guide %s: step order must be positive", g.Slug)
+			r.errorf(CodeStepOrderInvalid, []any{"steps", i, "order"}, "guide %s: step order must be positive", g.Slug)
+			orderingValid = false
 		}
 		if step.Title == "" {
-			return fmt.Errorf("guide %s: step %d title cannot be empty", g.Slug, step.Order)
+			r.errorf(CodeStepTitleRequired, []any{"steps", i, "title"}, "guide %s: step %d title cannot be empty", g.Slug, step.Order)
 		}
 		if step.Instruction == "" {
-			return fmt.Errorf("guide %s: step %d instruction cannot be empty", g.Slug, step.Order)
+			r.errorf(CodeStepInstructionRequired, []any{"steps", i, "instruction"}, "guide %s: step %d instruction cannot be empty", g.Slug, step.Order)
 		}
 		if stepOrders[step.Order] {
-			return fmt.Errorf("guide %s: duplicate step order %d", g.Slug, step.Order)
+			r.errorf(CodeStepOrderDuplicate, []any{"steps", i, "order"}, "guide %s: duplicate step order %d", g.Slug, step.Order)
+			orderingValid = false
 		}
 		stepOrders[step.Order] = true
+		stepIndexes[step.Order] = i
 		orders = append(orders, step.Order)
 
-		for _, doc := range step.Docs {
+		for j, doc := range step.Docs {
+			field := []any{"steps", i, "docs", j}
 			if doc.Title == "" {
-				return fmt.Errorf("guide %s: step %d doc title cannot be empty", g.Slug, step.Order)
+				r.errorf(CodeDocTitleRequired, append(field, "title"), "guide %s: step %d doc title cannot be empty", g.Slug, step.Order)
 			}
 			if doc.URL == "" {
-				return fmt.Errorf("guide %s: step %d doc URL cannot be empty", g.Slug, step.Order)
+				r.errorf(CodeDocURLRequired, append(field, "url"), "guide %s: step %d doc URL cannot be empty", g.Slug, step.Order)
+				continue
 			}
-			if _, err := url.Parse(doc.URL); err != nil {
-				return fmt.Errorf("guide %s: step %d doc URL %q is malformed: %w", g.Slug, step.Order, doc.URL, err)
+			parsedURL, err := url.Parse(doc.URL)
+			if err != nil {
+				r.errorf(CodeDocURLMalformed, append(field, "url"), "guide %s: step %d doc URL %q is malformed: %v", g.Slug, step.Order, doc.URL, err)
+				continue
 			}
-			parsedURL, _ := url.Parse(doc.URL)
 			if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-				return fmt.Errorf("guide %s: step %d doc URL %q must use http or https scheme", g.Slug, step.Order, doc.URL)
+				r.errorf(CodeDocURLScheme, append(field, "url"), "guide %s: step %d doc URL %q must use http or https scheme", g.Slug, step.Order, doc.URL)
 			}
 		}
 	}
 
-	sort.Ints(orders)
-	for i, order := range orders {
-		expectedOrder := i + 1
-		if order != expectedOrder {
-			return fmt.Errorf("guide %s: steps must be sequentially ordered starting at 1, found order %d at position %d", g.Slug, order, expectedOrder)
+	// Gaps are only meaningful once every order is positive and unique.
+	if orderingValid {
+		sort.Ints(orders)
+		for i, order := range orders {
+			expectedOrder := i + 1
+			if order != expectedOrder {
+				r.errorf(CodeStepOrderNotSequential, []any{"steps", stepIndexes[order], "order"}, "guide %s: steps must be sequentially ordered starting at 1, found order %d at position %d", g.Slug, order, expectedOrder)
+				break
+			}
 		}
 	}
 
-	if g.Metadata.MinutesToComplete < 0 {
-		return fmt.Errorf("guide %s: minutes to complete cannot be negative", g.Slug)
+	if g.Metadata.MinutesToComplete < 1 {
+		r.errorf(CodeGuideMinutesInvalid, []any{"metadata", "minutesToComplete"}, "guide %s: minutes to complete must be at least 1", g.Slug)
 	}
-
-	return nil
 }
@@ -0,0 +1,64 @@
+package userguides
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_DiskMatchesEmbedded(t *testing.T) {
+	embedded, err := Load(guidesFS)
+	if err != nil {
+		t.Fatalf("Load(guidesFS) returned error: %v", err)
+	}
+
+	disk, err := Load(os.DirFS("."))
+	if err != nil {
+		t.Fatalf("Load(os.DirFS) returned error: %v", err)
+	}
+
+	if len(disk.Groups) != len(embedded.Groups) {
+		t.Errorf("expected %d groups from disk, got %d", len(embedded.Groups), len(disk.Groups))
+	}
+}
+
+func TestLoad_WithRoot(t *testing.T) {
+	f := fstest.MapFS{
+		"mygroup/group.yaml":               {Data: validGroupYAML()},
+		"mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"mygroup/mychapter/guide-one.yaml": {Data: validGuideYAML("guide-one", 1)},
+	}
+
+	if _, err := Load(f); err == nil {
+		t.Error("expected error when the default root does not exist, got nil")
+	}
+
+	lib, err := Load(f, WithRoot("."))
+	if err != nil {
+		t.Fatalf("expected no error with WithRoot(\".\"), got: %v", err)
+	}
+	if len(lib.Groups) != 1 || lib.Groups[0].Slug != "mygroup" {
+		t.Errorf("expected a single group 'mygroup', got %+v", lib.Groups)
+	}
+}
+
+func TestLoad_WithCrossReferenceChecks(t *testing.T) {
+	guide := strings.Replace(string(validGuideYAML("guide-one", 1)), "recommendedGuideIds: []", "recommendedGuideIds: [\"lives-elsewhere\"]", 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	_, err := Load(f)
+	if err == nil {
+		t.Error("expected error for unknown recommended guide, got nil")
+	} else if !strings.Contains(err.Error(), "lives-elsewhere") {
+		t.Errorf("expected error message to mention 'lives-elsewhere', got: %v", err)
+	}
+
+	if _, err := Load(f, WithCrossReferenceChecks(false)); err != nil {
+		t.Errorf("expected no error with cross-reference checks disabled, got: %v", err)
+	}
+}
@@ -16,9 +16,18 @@ func validChapterYAML(ordering int) []byte {
 	return []byte("name: \"Test Chapter\"\ndescription: \"test\"\nordering: " + itoa(ordering) + "\n")
 }
 
+// validGuideMetadataYAML holds the metadata fields, other than the title,
+// that the guide schema requires.
+const validGuideMetadataYAML = `  description: "test"
+  labels: ["test"]
+  difficulty: "easy"
+  minutesToComplete: 5
+  prerequisites: []
+`
+
 // validGuideYAML returns a minimal valid guide yaml with the given slug and ordering
 func validGuideYAML(slug string, ordering int) []byte {
-	return []byte("slug: " + slug + "\nordering: " + itoa(ordering) + "\nmetadata:\n  title: \"" + slug + "\"\nsteps:\n  - order: 1\n    title: \"Step\"\n    instruction: \"Do this\"\ncompletion:\n  successMessage: \"Done\"\n")
+	return []byte("slug: " + slug + "\nordering: " + itoa(ordering) + "\nmetadata:\n  title: \"" + slug + "\"\n" + validGuideMetadataYAML + "steps:\n  - order: 1\n    title: \"Step\"\n    instruction: \"Do this\"\ncompletion:\n  successMessage: \"Done\"\n  recommendedGuideIds: []\n")
 }
 
 func itoa(i int) string {
@@ -45,13 +54,13 @@ func itoa(i int) string {
 
 func TestOrderingValidation_DuplicateGuideOrdering(t *testing.T) {
 	f := fstest.MapFS{
-		"guides/mygroup/group.yaml":                {Data: validGroupYAML()},
-		"guides/mygroup/mychapter/chapter.yaml":    {Data: validChapterYAML(1)},
-		"guides/mygroup/mychapter/guide-one.yaml":  {Data: validGuideYAML("guide-one", 1)},
-		"guides/mygroup/mychapter/guide-two.yaml":  {Data: validGuideYAML("guide-two", 1)}, // duplicate ordering: 1
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: validGuideYAML("guide-one", 1)},
+		"guides/mygroup/mychapter/guide-two.yaml": {Data: validGuideYAML("guide-two", 1)}, // duplicate ordering: 1
 	}
 
-	_, err := parse(f)
+	_, err := Load(f)
 	if err == nil {
 		t.Error("expected error for duplicate guide ordering within a chapter, got nil")
 	} else if !strings.Contains(err.Error(), "ordering") {
@@ -61,14 +70,14 @@ func TestOrderingValidation_DuplicateGuideOrdering(t *testing.T) {
 
 func TestOrderingValidation_DuplicateChapterOrdering(t *testing.T) {
 	f := fstest.MapFS{
-		"guides/mygroup/group.yaml":                 {Data: validGroupYAML()},
-		"guides/mygroup/chapter-one/chapter.yaml":   {Data: validChapterYAML(1)},
-		"guides/mygroup/chapter-one/guide-a.yaml":   {Data: validGuideYAML("guide-a", 1)},
-		"guides/mygroup/chapter-two/chapter.yaml":   {Data: validChapterYAML(1)}, // duplicate ordering: 1
-		"guides/mygroup/chapter-two/guide-b.yaml":   {Data: validGuideYAML("guide-b", 1)},
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/chapter-one/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/chapter-one/guide-a.yaml": {Data: validGuideYAML("guide-a", 1)},
+		"guides/mygroup/chapter-two/chapter.yaml": {Data: validChapterYAML(1)}, // duplicate ordering: 1
+		"guides/mygroup/chapter-two/guide-b.yaml": {Data: validGuideYAML("guide-b", 1)},
 	}
 
-	_, err := parse(f)
+	_, err := Load(f)
 	if err == nil {
 		t.Error("expected error for duplicate chapter ordering within a group, got nil")
 	} else if !strings.Contains(err.Error(), "ordering") {
@@ -78,14 +87,120 @@ func TestOrderingValidation_DuplicateChapterOrdering(t *testing.T) {
 
 func TestOrderingValidation_UniqueOrderingsPass(t *testing.T) {
 	f := fstest.MapFS{
-		"guides/mygroup/group.yaml":                {Data: validGroupYAML()},
-		"guides/mygroup/mychapter/chapter.yaml":    {Data: validChapterYAML(1)},
-		"guides/mygroup/mychapter/guide-one.yaml":  {Data: validGuideYAML("guide-one", 1)},
-		"guides/mygroup/mychapter/guide-two.yaml":  {Data: validGuideYAML("guide-two", 2)},
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: validGuideYAML("guide-one", 1)},
+		"guides/mygroup/mychapter/guide-two.yaml": {Data: validGuideYAML("guide-two", 2)},
 	}
 
-	_, err := parse(f)
+	_, err := Load(f)
 	if err != nil {
 		t.Errorf("expected no error for unique guide orderings, got: %v", err)
 	}
 }
+
+// groupYAMLWithOrdering returns a minimal valid group.yaml with the given ordering
+func groupYAMLWithOrdering(ordering int) []byte {
+	return []byte("name: \"Test Group\"\ndescription: \"test\"\nskillLevel: BEGINNER\nordering: " + itoa(ordering) + "\n")
+}
+
+func TestOrderingSort_ShuffledFileNames(t *testing.T) {
+	// File names sort in the opposite order to the ordering fields, so the
+	// result must not depend on fs.ReadDir order.
+	f := fstest.MapFS{
+		"guides/a-group/group.yaml":              {Data: groupYAMLWithOrdering(3)},
+		"guides/a-group/a-chapter/chapter.yaml":  {Data: validChapterYAML(1)},
+		"guides/a-group/a-chapter/guide-x.yaml":  {Data: validGuideYAML("guide-x", 1)},
+		"guides/b-group/group.yaml":              {Data: groupYAMLWithOrdering(2)},
+		"guides/b-group/a-chapter/chapter.yaml":  {Data: validChapterYAML(1)},
+		"guides/b-group/a-chapter/guide-y.yaml":  {Data: validGuideYAML("guide-y", 1)},
+		"guides/c-group/group.yaml":              {Data: groupYAMLWithOrdering(1)},
+		"guides/c-group/a-chapter/chapter.yaml":  {Data: validChapterYAML(3)},
+		"guides/c-group/a-chapter/guide-a.yaml":  {Data: validGuideYAML("guide-a", 1)},
+		"guides/c-group/b-chapter/chapter.yaml":  {Data: validChapterYAML(2)},
+		"guides/c-group/b-chapter/guide-b.yaml":  {Data: validGuideYAML("guide-b", 1)},
+		"guides/c-group/c-chapter/chapter.yaml":  {Data: validChapterYAML(1)},
+		"guides/c-group/c-chapter/01-zeta.yaml":  {Data: validGuideYAML("zeta", 3)},
+		"guides/c-group/c-chapter/02-eta.yaml":   {Data: validGuideYAML("eta", 1)},
+		"guides/c-group/c-chapter/03-theta.yaml": {Data: validGuideYAML("theta", 2)},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var groups []string
+	for _, group := range lib.Groups {
+		groups = append(groups, group.Slug)
+	}
+	if got := strings.Join(groups, ","); got != "c-group,b-group,a-group" {
+		t.Errorf("expected groups sorted by ordering, got %s", got)
+	}
+
+	var chapters []string
+	for _, chapter := range lib.Groups[0].Chapters {
+		chapters = append(chapters, chapter.Slug)
+	}
+	if got := strings.Join(chapters, ","); got != "c-chapter,b-chapter,a-chapter" {
+		t.Errorf("expected chapters sorted by ordering, got %s", got)
+	}
+
+	var guides []string
+	for _, guide := range lib.Groups[0].Chapters[0].Guides {
+		guides = append(guides, guide.Slug)
+	}
+	if got := strings.Join(guides, ","); got != "eta,theta,zeta" {
+		t.Errorf("expected guides sorted by ordering, got %s", got)
+	}
+
+	// The index must point at the sorted positions.
+	if guide, ok := lib.GuideBySlug("zeta"); !ok || guide.Ordering != 3 {
+		t.Errorf("GuideBySlug(\"zeta\") returned %+v", guide)
+	}
+}
+
+func TestOrderingSort_GroupTieBreaksOnSlug(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/b-group/group.yaml":             {Data: groupYAMLWithOrdering(1)},
+		"guides/b-group/a-chapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/b-group/a-chapter/guide-b.yaml": {Data: validGuideYAML("guide-b", 1)},
+		"guides/a-group/group.yaml":             {Data: groupYAMLWithOrdering(1)},
+		"guides/a-group/a-chapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/a-group/a-chapter/guide-a.yaml": {Data: validGuideYAML("guide-a", 1)},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if lib.Groups[0].Slug != "a-group" || lib.Groups[1].Slug != "b-group" {
+		t.Errorf("expected groups with equal ordering sorted by slug, got %s,%s", lib.Groups[0].Slug, lib.Groups[1].Slug)
+	}
+}
+
+func TestOrderingSort_Steps(t *testing.T) {
+	guide := "slug: steps\nordering: 1\nmetadata:\n  title: \"Steps\"\n" + validGuideMetadataYAML + "steps:\n" +
+		"  - order: 3\n    title: \"Third\"\n    instruction: \"Do this\"\n" +
+		"  - order: 1\n    title: \"First\"\n    instruction: \"Do this\"\n" +
+		"  - order: 2\n    title: \"Second\"\n    instruction: \"Do this\"\n" +
+		"completion:\n  successMessage: \"Done\"\n  recommendedGuideIds: []\n"
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/steps.yaml":   {Data: []byte(guide)},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	steps := lib.Groups[0].Chapters[0].Guides[0].Steps
+	for i, step := range steps {
+		if step.Order != i+1 {
+			t.Errorf("expected step %d at position %d, got order %d (%s)", i+1, i, step.Order, step.Title)
+		}
+	}
+}
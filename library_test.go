@@ -1,11 +1,13 @@
 package userguides_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
@@ -75,21 +77,42 @@ func TestGuidesLoad(t *testing.T) {
 	}
 }
 
-func TestFoundationsGroup(t *testing.T) {
-	lib, err := userguides.Guides()
+func TestGuidesIsMemoized(t *testing.T) {
+	first, err := userguides.Guides()
 	if err != nil {
 		t.Fatalf("Guides() returned error: %v", err)
 	}
 
-	var foundations *userguides.Group
-	for i := range lib.Groups {
-		if lib.Groups[i].Slug == "foundations" {
-			foundations = &lib.Groups[i]
-			break
+	var wg sync.WaitGroup
+	libs := make([]*userguides.Library, 8)
+	for i := range libs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			libs[i], _ = userguides.Guides()
+		}()
+	}
+	wg.Wait()
+
+	for i, lib := range libs {
+		if lib != first {
+			t.Errorf("call %d returned a different *Library than the first call", i)
 		}
 	}
 
-	if foundations == nil {
+	if userguides.MustGuides() != first {
+		t.Error("MustGuides() returned a different *Library than Guides()")
+	}
+}
+
+func TestFoundationsGroup(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	foundations, ok := lib.GroupBySlug("foundations")
+	if !ok {
 		t.Fatal("Expected 'foundations' group to exist")
 	}
 
@@ -137,22 +160,34 @@ func TestOrdering(t *testing.T) {
 		t.Fatalf("Guides() returned error: %v", err)
 	}
 
-	for _, group := range lib.Groups {
+	for i, group := range lib.Groups {
 		t.Logf("Group %s has ordering: %d", group.Name, group.Ordering)
 
+		if i > 0 && lib.Groups[i-1].Ordering > group.Ordering {
+			t.Errorf("Group %s is listed after a group with a higher ordering", group.Name)
+		}
+
 		chapterOrderings := make(map[int]string)
-		for _, chapter := range group.Chapters {
+		for j, chapter := range group.Chapters {
 			t.Logf("  Chapter %s has ordering: %d", chapter.Name, chapter.Ordering)
 
+			if j > 0 && group.Chapters[j-1].Ordering > chapter.Ordering {
+				t.Errorf("Group %s: chapter %q is listed after a chapter with a higher ordering", group.Name, chapter.Name)
+			}
+
 			if prev, ok := chapterOrderings[chapter.Ordering]; ok {
 				t.Errorf("Group %s: chapters %q and %q share ordering %d", group.Name, prev, chapter.Name, chapter.Ordering)
 			}
 			chapterOrderings[chapter.Ordering] = chapter.Name
 
 			guideOrderings := make(map[int]string)
-			for _, guide := range chapter.Guides {
+			for k, guide := range chapter.Guides {
 				t.Logf("    Guide %s has ordering: %d", guide.Metadata.Title, guide.Ordering)
 
+				if k > 0 && chapter.Guides[k-1].Ordering > guide.Ordering {
+					t.Errorf("Chapter %s/%s: guide %q is listed after a guide with a higher ordering", group.Name, chapter.Name, guide.Metadata.Title)
+				}
+
 				if prev, ok := guideOrderings[guide.Ordering]; ok {
 					t.Errorf("Chapter %s/%s: guides %q and %q share ordering %d", group.Name, chapter.Name, prev, guide.Metadata.Title, guide.Ordering)
 				}
@@ -210,6 +245,24 @@ func TestValidationRules(t *testing.T) {
 			expectErr: true,
 			errMsg:    "invalid difficulty",
 		},
+		{
+			name: "missing difficulty",
+			guide: userguides.Guide{
+				Slug:     "test-guide",
+				Ordering: 1,
+				Metadata: userguides.GuideMetadata{
+					Title:             "Test Guide",
+					Labels:            []string{"test"},
+					MinutesToComplete: 5,
+				},
+				Steps: []userguides.GuideStep{
+					{Order: 1, Title: "Step 1", Instruction: "Do this"},
+				},
+				Completion: userguides.GuideCompletion{},
+			},
+			expectErr: true,
+			errMsg:    "difficulty cannot be empty",
+		},
 		{
 			name: "empty label",
 			guide: userguides.Guide{
@@ -290,6 +343,29 @@ func TestValidationRules(t *testing.T) {
 	}
 }
 
+func TestSchemasAreUpToDate(t *testing.T) {
+	schemas := map[string]func() ([]byte, error){
+		"schema/group_schema.json":            userguides.GroupSchema,
+		"schema/chapter_schema.json":          userguides.ChapterSchema,
+		"schema/guide_schema.json":            userguides.GuideSchema,
+		"schema/validation_input_schema.json": userguides.ValidationInputSchema,
+	}
+
+	for path, schema := range schemas {
+		want, err := schema()
+		if err != nil {
+			t.Fatalf("Failed to generate %s: %v", path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read schema: %v", err)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("%s is stale; run go generate", path)
+		}
+	}
+}
+
 func compileSchema(t *testing.T, schemaPath string) *jsonschema.Schema {
 	t.Helper()
 
@@ -414,21 +490,12 @@ func TestPrerequisiteGuideSlugsExist(t *testing.T) {
 		t.Fatalf("Guides() returned error: %v", err)
 	}
 
-	allGuideSlugs := make(map[string]bool)
-	for _, group := range lib.Groups {
-		for _, chapter := range group.Chapters {
-			for _, guide := range chapter.Guides {
-				allGuideSlugs[guide.Slug] = true
-			}
-		}
-	}
-
 	for _, group := range lib.Groups {
 		for _, chapter := range group.Chapters {
 			for _, guide := range chapter.Guides {
 				guidePath := group.Slug + "/" + chapter.Slug + "/" + guide.Slug
 				for _, prereq := range guide.PrerequisiteGuideSlugs {
-					if !allGuideSlugs[prereq] {
+					if _, ok := lib.GuideBySlug(prereq); !ok {
 						t.Errorf("Guide %s references non-existent prerequisite guide: %s", guidePath, prereq)
 					}
 				}
@@ -436,3 +503,66 @@ func TestPrerequisiteGuideSlugsExist(t *testing.T) {
 		}
 	}
 }
+
+func TestLookups(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	for gi := range lib.Groups {
+		group := &lib.Groups[gi]
+		if got, ok := lib.GroupBySlug(group.Slug); !ok || got != group {
+			t.Errorf("GroupBySlug(%q) did not return the group", group.Slug)
+		}
+
+		for ci := range group.Chapters {
+			chapter := &group.Chapters[ci]
+			if got, ok := lib.ChapterByPath(group.Slug, chapter.Slug); !ok || got != chapter {
+				t.Errorf("ChapterByPath(%q, %q) did not return the chapter", group.Slug, chapter.Slug)
+			}
+
+			for ki := range chapter.Guides {
+				guide := &chapter.Guides[ki]
+				if got, ok := lib.GuideBySlug(guide.Slug); !ok || got != guide {
+					t.Errorf("GuideBySlug(%q) did not return the guide", guide.Slug)
+				}
+
+				parentGroup, parentChapter, ok := lib.ParentOf(guide.Slug)
+				if !ok || parentGroup != group || parentChapter != chapter {
+					t.Errorf("ParentOf(%q) did not return %s/%s", guide.Slug, group.Slug, chapter.Slug)
+				}
+			}
+		}
+	}
+
+	if _, ok := lib.GroupBySlug("no-such-group"); ok {
+		t.Error("GroupBySlug returned a group for an unknown slug")
+	}
+	if _, ok := lib.ChapterByPath("foundations", "no-such-chapter"); ok {
+		t.Error("ChapterByPath returned a chapter for an unknown slug")
+	}
+	if _, ok := lib.GuideBySlug("no-such-guide"); ok {
+		t.Error("GuideBySlug returned a guide for an unknown slug")
+	}
+	if _, _, ok := lib.ParentOf("no-such-guide"); ok {
+		t.Error("ParentOf returned a parent for an unknown slug")
+	}
+}
+
+func TestLookups_HandBuiltLibrary(t *testing.T) {
+	lib := &userguides.Library{
+		Groups: []userguides.Group{{
+			Slug: "group",
+			Chapters: []userguides.Chapter{{
+				Slug:   "chapter",
+				Guides: []userguides.Guide{{Slug: "guide"}},
+			}},
+		}},
+	}
+
+	group, chapter, ok := lib.ParentOf("guide")
+	if !ok || group.Slug != "group" || chapter.Slug != "chapter" {
+		t.Errorf("ParentOf on a hand-built library returned %v, %v, %v", group, chapter, ok)
+	}
+}
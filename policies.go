@@ -0,0 +1,57 @@
+package userguides
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spacelift-io/spacelift-user-guides-library/internal/policy"
+)
+
+// validateStepPolicies compiles the validation policy of every step, so
+// that a broken policy fails the load instead of the first evaluation.
+// References to input are type-checked against ValidationInput.
+func (g Guide) validateStepPolicies(r *ValidationReport) {
+	codes := map[policy.Kind]RuleCode{
+		policy.KindSyntax:      CodeValidationSyntax,
+		policy.KindPackage:     CodeValidationPackage,
+		policy.KindMissingRule: CodeValidationRuleMissing,
+		policy.KindCompile:     CodeValidationCompile,
+		policy.KindType:        CodeValidationType,
+	}
+
+	schema, err := validationInputSchemaDoc()
+	if err != nil {
+		r.errorf(CodeValidationType, []any{"steps"}, "guide %s: cannot type-check validations: %v", g.Slug, err)
+		return
+	}
+
+	for i, step := range g.Steps {
+		if step.Validation == "" {
+			continue
+		}
+
+		name := fmt.Sprintf("%s/step-%d.rego", g.Slug, step.Order)
+		_, errs := policy.Compile(name, step.Validation, schema)
+		for _, err := range errs {
+			field := []any{"steps", i, "validation"}
+			if err.Line > 0 {
+				field = append(field, textOffset(lineOffset(step.Validation, err.Line)))
+			}
+			r.errorf(codes[err.Kind], field, "guide %s: step %d validation: %s", g.Slug, step.Order, err.Message)
+		}
+	}
+}
+
+// lineOffset returns the byte offset at which the given 1-based line of s
+// starts.
+func lineOffset(s string, line int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		i := strings.IndexByte(s[offset:], '\n')
+		if i < 0 {
+			break
+		}
+		offset += i + 1
+	}
+	return offset
+}
@@ -0,0 +1,103 @@
+package userguides
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// guideYAMLWithValidation returns a single-step guide yaml whose step
+// validation is the given policy, indented as a block scalar.
+func guideYAMLWithValidation(policy string) []byte {
+	return []byte(`slug: guide-one
+ordering: 1
+metadata:
+  title: "Guide"
+` + validGuideMetadataYAML + `steps:
+  - order: 1
+    title: "Step"
+    instruction: "Do it"
+    validationHint: "Do it first."
+    validation: |
+` + indent(policy, "      ") + `completion:
+  successMessage: "Done"
+  recommendedGuideIds: []
+`)
+}
+
+func indent(s, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			b.WriteString(prefix)
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func TestStepPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		code   RuleCode
+		line   int
+	}{
+		{
+			name:   "syntax",
+			policy: "package spacelift\n\nvalid if {\n  input.stacks[\n}\n",
+			code:   CodeValidationSyntax,
+			line:   20,
+		},
+		{
+			name:   "package",
+			policy: "package other\n\nvalid if { true }\n",
+			code:   CodeValidationPackage,
+			line:   16,
+		},
+		{
+			name:   "missing rule",
+			policy: "package spacelift\n\nallow if { true }\n",
+			code:   CodeValidationRuleMissing,
+			line:   15,
+		},
+		{
+			name:   "compile",
+			policy: "package spacelift\n\nvalid if { x }\n",
+			code:   CodeValidationCompile,
+			line:   18,
+		},
+		{
+			name:   "unknown input field",
+			policy: "package spacelift\n\nvalid if {\n  some stack in input.stacks\n  stack.autodeplyo\n}\n",
+			code:   CodeValidationType,
+			line:   20,
+		},
+		{
+			name:   "unknown input collection",
+			policy: "package spacelift\n\nvalid if {\n  some stack in input.stack\n}\n",
+			code:   CodeValidationType,
+			line:   19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fstest.MapFS{
+				"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+				"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+				"guides/mygroup/mychapter/guide-one.yaml": {Data: guideYAMLWithValidation(tt.policy)},
+			}
+
+			problems := loadProblems(t, f)
+			if len(problems) == 0 {
+				t.Fatal("expected the broken policy to fail the load")
+			}
+
+			p := problems[0]
+			if p.Code != tt.code || p.Line != tt.line {
+				t.Errorf("expected %s on line %d, got %s on line %d: %v", tt.code, tt.line, p.Code, p.Line, p)
+			}
+		})
+	}
+}
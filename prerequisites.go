@@ -0,0 +1,263 @@
+package userguides
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// prerequisiteCycles returns the cycles in the prerequisite graph of lib.
+// Each cycle starts and ends with the same slug, beginning with the guide
+// that comes first in display order, and is reported once however many
+// guides lead into it. Unknown slugs and self-references are ignored; they
+// are reported separately.
+func prerequisiteCycles(lib *Library) [][]string {
+	var order []string
+	position := make(map[string]int)
+	edges := make(map[string][]string)
+	for _, group := range lib.Groups {
+		for _, chapter := range group.Chapters {
+			for _, guide := range chapter.Guides {
+				position[guide.Slug] = len(order)
+				order = append(order, guide.Slug)
+				edges[guide.Slug] = guide.PrerequisiteGuideSlugs
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var visit func(slug string)
+	visit = func(slug string) {
+		state[slug] = visiting
+		stack = append(stack, slug)
+
+		for _, prereq := range edges[slug] {
+			if _, ok := edges[prereq]; !ok || prereq == slug {
+				continue
+			}
+			switch state[prereq] {
+			case unvisited:
+				visit(prereq)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != prereq {
+					start--
+				}
+				members := stack[start:]
+				first := 0
+				for i, member := range members {
+					if position[member] < position[members[first]] {
+						first = i
+					}
+				}
+				cycle := append(append([]string(nil), members[first:]...), members[:first]...)
+				cycle = append(cycle, cycle[0])
+				if key := strings.Join(cycle, "\x00"); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[slug] = done
+	}
+
+	for _, slug := range order {
+		if state[slug] == unvisited {
+			visit(slug)
+		}
+	}
+
+	return cycles
+}
+
+// PrerequisiteGraph is the dependency graph formed by the
+// prerequisiteGuideSlugs of every guide in a Library. All slices it returns
+// are in learning order: a guide never appears before its prerequisites, and
+// guides that are independent of each other keep their display order.
+type PrerequisiteGraph struct {
+	order         []string
+	rank          map[string]int
+	prerequisites map[string][]string
+	dependents    map[string][]string
+}
+
+// NewPrerequisiteGraph builds the prerequisite graph of lib. It fails if
+// guide slugs are not unique, if a guide lists an unknown guide or itself as
+// a prerequisite, or if the prerequisites form a cycle; Load rejects such
+// libraries unless cross-reference checks are disabled.
+func NewPrerequisiteGraph(lib *Library) (*PrerequisiteGraph, error) {
+	var display []string
+	declared := make(map[string][]string)
+	for _, group := range lib.Groups {
+		for _, chapter := range group.Chapters {
+			for _, guide := range chapter.Guides {
+				if _, dup := declared[guide.Slug]; dup {
+					return nil, fmt.Errorf("duplicate guide slug %s", guide.Slug)
+				}
+				display = append(display, guide.Slug)
+				declared[guide.Slug] = guide.PrerequisiteGuideSlugs
+			}
+		}
+	}
+
+	g := &PrerequisiteGraph{
+		rank:          make(map[string]int),
+		prerequisites: make(map[string][]string),
+		dependents:    make(map[string][]string),
+	}
+
+	for _, slug := range display {
+		for _, prereq := range declared[slug] {
+			if prereq == slug {
+				return nil, fmt.Errorf("guide %s lists itself as a prerequisite", slug)
+			}
+			if _, ok := declared[prereq]; !ok {
+				return nil, fmt.Errorf("guide %s has unknown prerequisite %s", slug, prereq)
+			}
+		}
+	}
+	if cycles := prerequisiteCycles(lib); len(cycles) > 0 {
+		return nil, fmt.Errorf("cyclic prerequisites: %s", strings.Join(cycles[0], " -> "))
+	}
+
+	// Kahn's algorithm, always picking the ready guide that comes first in
+	// display order.
+	pending := make(map[string]int)
+	for _, slug := range display {
+		pending[slug] = len(declared[slug])
+		for _, prereq := range declared[slug] {
+			g.dependents[prereq] = append(g.dependents[prereq], slug)
+		}
+	}
+	for len(g.order) < len(display) {
+		for _, slug := range display {
+			if _, placed := g.rank[slug]; placed || pending[slug] > 0 {
+				continue
+			}
+			g.rank[slug] = len(g.order)
+			g.order = append(g.order, slug)
+			for _, dependent := range g.dependents[slug] {
+				pending[dependent]--
+			}
+			break
+		}
+	}
+
+	for _, slug := range display {
+		g.prerequisites[slug] = g.sorted(declared[slug])
+		g.dependents[slug] = g.sorted(g.dependents[slug])
+	}
+
+	return g, nil
+}
+
+// sorted returns a copy of slugs in learning order.
+func (g *PrerequisiteGraph) sorted(slugs []string) []string {
+	out := slices.Clone(slugs)
+	sort.Slice(out, func(i, j int) bool {
+		return g.rank[out[i]] < g.rank[out[j]]
+	})
+	return slices.Compact(out)
+}
+
+// TopologicalOrder returns every guide slug in learning order.
+func (g *PrerequisiteGraph) TopologicalOrder() []string {
+	return slices.Clone(g.order)
+}
+
+// Prerequisites returns the guides that the given guide lists directly.
+func (g *PrerequisiteGraph) Prerequisites(slug string) []string {
+	return slices.Clone(g.prerequisites[slug])
+}
+
+// AllPrerequisites returns every guide that must be completed, directly or
+// transitively, before the given guide.
+func (g *PrerequisiteGraph) AllPrerequisites(slug string) []string {
+	return g.sorted(g.walk(slug, g.prerequisites))
+}
+
+// Dependents returns the guides that list the given guide directly.
+func (g *PrerequisiteGraph) Dependents(slug string) []string {
+	return slices.Clone(g.dependents[slug])
+}
+
+// AllDependents returns every guide that requires the given guide, directly
+// or transitively.
+func (g *PrerequisiteGraph) AllDependents(slug string) []string {
+	return g.sorted(g.walk(slug, g.dependents))
+}
+
+// walk returns the slugs reachable from slug through edges, excluding slug.
+func (g *PrerequisiteGraph) walk(slug string, edges map[string][]string) []string {
+	seen := map[string]bool{slug: true}
+	var out []string
+	queue := []string{slug}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if !seen[next] {
+				seen[next] = true
+				out = append(out, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return out
+}
+
+// MissingPrerequisites returns the direct prerequisites of the given guide
+// that are not in completed. A guide is locked while this is non-empty.
+func (g *PrerequisiteGraph) MissingPrerequisites(slug string, completed []string) []string {
+	done := make(map[string]bool, len(completed))
+	for _, c := range completed {
+		done[c] = true
+	}
+
+	var missing []string
+	for _, prereq := range g.prerequisites[slug] {
+		if !done[prereq] {
+			missing = append(missing, prereq)
+		}
+	}
+	return missing
+}
+
+// Frontier returns the guides that are not in completed but whose direct
+// prerequisites all are, i.e. the guides a user can start next.
+func (g *PrerequisiteGraph) Frontier(completed []string) []string {
+	done := make(map[string]bool, len(completed))
+	for _, c := range completed {
+		done[c] = true
+	}
+
+	var frontier []string
+	for _, slug := range g.order {
+		if done[slug] {
+			continue
+		}
+		unlocked := true
+		for _, prereq := range g.prerequisites[slug] {
+			if !done[prereq] {
+				unlocked = false
+				break
+			}
+		}
+		if unlocked {
+			frontier = append(frontier, slug)
+		}
+	}
+	return frontier
+}
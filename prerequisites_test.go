@@ -0,0 +1,199 @@
+package userguides
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// guideYAMLWithPrerequisites returns a minimal valid guide yaml that lists
+// the given prerequisite guide slugs
+func guideYAMLWithPrerequisites(slug string, ordering int, prerequisites ...string) []byte {
+	var b strings.Builder
+	b.WriteString("prerequisiteGuideSlugs:\n")
+	for _, prereq := range prerequisites {
+		b.WriteString("  - \"" + prereq + "\"\n")
+	}
+	return append([]byte(b.String()), validGuideYAML(slug, ordering)...)
+}
+
+func loadProblems(t *testing.T, f fstest.MapFS) []Problem {
+	t.Helper()
+
+	_, err := Load(f)
+	if err == nil {
+		return nil
+	}
+
+	var report *ValidationReport
+	if !errors.As(err, &report) {
+		t.Fatalf("expected a *ValidationReport, got %T: %v", err, err)
+	}
+	return report.Problems
+}
+
+func TestPrerequisites_Valid(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: validGuideYAML("a", 1)},
+		"guides/mygroup/mychapter/b.yaml":       {Data: guideYAMLWithPrerequisites("b", 2, "a")},
+		"guides/mygroup/mychapter/c.yaml":       {Data: guideYAMLWithPrerequisites("c", 3, "a", "b")},
+	}
+
+	if problems := loadProblems(t, f); len(problems) != 0 {
+		t.Errorf("expected no problems, got: %v", problems)
+	}
+}
+
+func TestPrerequisites_UnknownAndSelf(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: guideYAMLWithPrerequisites("a", 1, "a", "nowhere")},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got: %v", problems)
+	}
+
+	if problems[0].Code != CodePrerequisiteSelf || problems[0].Line != 2 {
+		t.Errorf("expected %s on line 2, got %s on line %d", CodePrerequisiteSelf, problems[0].Code, problems[0].Line)
+	}
+	if problems[1].Code != CodePrerequisiteNotFound || problems[1].Line != 3 {
+		t.Errorf("expected %s on line 3, got %s on line %d", CodePrerequisiteNotFound, problems[1].Code, problems[1].Line)
+	}
+}
+
+func TestPrerequisites_Cycle(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: guideYAMLWithPrerequisites("a", 1, "c")},
+		"guides/mygroup/mychapter/b.yaml":       {Data: guideYAMLWithPrerequisites("b", 2, "a")},
+		"guides/mygroup/mychapter/c.yaml":       {Data: guideYAMLWithPrerequisites("c", 3, "b")},
+		"guides/mygroup/mychapter/d.yaml":       {Data: guideYAMLWithPrerequisites("d", 4, "c")},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 1 {
+		t.Fatalf("expected the cycle to be reported once, got: %v", problems)
+	}
+
+	p := problems[0]
+	if p.Code != CodePrerequisiteCycle {
+		t.Errorf("expected %s, got %s", CodePrerequisiteCycle, p.Code)
+	}
+	if p.Path != "guides/mygroup/mychapter/a.yaml" || p.Line != 2 {
+		t.Errorf("expected the cycle to be reported at a.yaml:2, got %s:%d", p.Path, p.Line)
+	}
+	if !strings.Contains(p.Message, "a -> c -> b -> a") {
+		t.Errorf("expected the full cycle path in the message, got: %s", p.Message)
+	}
+}
+
+// prerequisiteLibrary builds a single-chapter library from slug and
+// prerequisite pairs, in display order.
+func prerequisiteLibrary(guides ...[]string) *Library {
+	chapter := Chapter{Slug: "chapter"}
+	for i, g := range guides {
+		chapter.Guides = append(chapter.Guides, Guide{
+			Slug:                   g[0],
+			Ordering:               i + 1,
+			PrerequisiteGuideSlugs: g[1:],
+		})
+	}
+	return &Library{Groups: []Group{{Slug: "group", Chapters: []Chapter{chapter}}}}
+}
+
+func TestPrerequisiteGraph(t *testing.T) {
+	// Display order differs from learning order: "advanced" is listed first
+	// but needs both "basics" and "intermediate".
+	lib := prerequisiteLibrary(
+		[]string{"advanced", "intermediate", "basics"},
+		[]string{"basics"},
+		[]string{"intermediate", "basics"},
+		[]string{"standalone"},
+		[]string{"expert", "advanced"},
+	)
+
+	g, err := NewPrerequisiteGraph(lib)
+	if err != nil {
+		t.Fatalf("NewPrerequisiteGraph returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want string
+	}{
+		{"TopologicalOrder", g.TopologicalOrder(), "basics,intermediate,advanced,standalone,expert"},
+		{"Prerequisites(advanced)", g.Prerequisites("advanced"), "basics,intermediate"},
+		{"AllPrerequisites(expert)", g.AllPrerequisites("expert"), "basics,intermediate,advanced"},
+		{"Dependents(basics)", g.Dependents("basics"), "intermediate,advanced"},
+		{"AllDependents(intermediate)", g.AllDependents("intermediate"), "advanced,expert"},
+		{"AllDependents(standalone)", g.AllDependents("standalone"), ""},
+		{"Frontier()", g.Frontier(nil), "basics,standalone"},
+		{"Frontier(basics)", g.Frontier([]string{"basics"}), "intermediate,standalone"},
+		{"Frontier(basics,intermediate,standalone)", g.Frontier([]string{"basics", "intermediate", "standalone"}), "advanced"},
+		{"MissingPrerequisites(advanced)", g.MissingPrerequisites("advanced", []string{"basics"}), "intermediate"},
+		{"Prerequisites(unknown)", g.Prerequisites("unknown"), ""},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(tt.got, ","); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrerequisiteGraph_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		lib    *Library
+		errMsg string
+	}{
+		{"self", prerequisiteLibrary([]string{"a", "a"}), "itself"},
+		{"unknown", prerequisiteLibrary([]string{"a", "nowhere"}), "unknown prerequisite nowhere"},
+		{"cycle", prerequisiteLibrary([]string{"a", "b"}, []string{"b", "a"}), "a -> b -> a"},
+		{"duplicate", prerequisiteLibrary([]string{"a"}, []string{"a"}), "duplicate guide slug a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPrerequisiteGraph(tt.lib)
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.errMsg)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got: %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestPrerequisiteGraph_EmbeddedLibrary(t *testing.T) {
+	lib, err := Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	g, err := NewPrerequisiteGraph(lib)
+	if err != nil {
+		t.Fatalf("NewPrerequisiteGraph returned error: %v", err)
+	}
+
+	position := make(map[string]int)
+	for i, slug := range g.TopologicalOrder() {
+		position[slug] = i
+	}
+	for slug, i := range position {
+		for _, prereq := range g.Prerequisites(slug) {
+			if position[prereq] >= i {
+				t.Errorf("guide %s is ordered before its prerequisite %s", slug, prereq)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package userguides
+
+import "slices"
+
+// releaseState returns the guide's release state, defaulting to published
+// for guides assembled by hand.
+func (g Guide) releaseState() ReleaseState {
+	if g.ReleaseState == "" {
+		return ReleaseStatePublished
+	}
+	return g.ReleaseState
+}
+
+// published reports whether the guide is served to every user.
+func (g Guide) published() bool {
+	return g.releaseState() == ReleaseStatePublished
+}
+
+// Filter returns a copy of the library holding only the guides in one of
+// the given release states. Chapters and groups left without guides are
+// dropped. Production serves lib.Filter(ReleaseStatePublished); internal
+// environments can include guides under test as well.
+//
+// The original library is not modified, and its warnings are carried over
+// to the copy.
+func (l *Library) Filter(states ...ReleaseState) *Library {
+	filtered := &Library{
+		Groups:   []Group{},
+		warnings: l.warnings,
+	}
+
+	for _, group := range l.Groups {
+		chapters := []Chapter{}
+		for _, chapter := range group.Chapters {
+			guides := []Guide{}
+			for _, guide := range chapter.Guides {
+				if slices.Contains(states, guide.releaseState()) {
+					guides = append(guides, guide)
+				}
+			}
+			if len(guides) > 0 {
+				chapter.Guides = guides
+				chapters = append(chapters, chapter)
+			}
+		}
+		if len(chapters) > 0 {
+			group.Chapters = chapters
+			filtered.Groups = append(filtered.Groups, group)
+		}
+	}
+
+	filtered.index = newLibraryIndex(filtered)
+	return filtered
+}
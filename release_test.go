@@ -0,0 +1,133 @@
+package userguides
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// guideYAMLWithReleaseState returns a minimal valid guide yaml with the
+// given releaseState.
+func guideYAMLWithReleaseState(slug string, ordering int, state string) []byte {
+	return append([]byte("releaseState: "+state+"\n"), validGuideYAML(slug, ordering)...)
+}
+
+func TestReleaseState_Parse(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: validGuideYAML("a", 1)},
+		"guides/mygroup/mychapter/b.yaml":       {Data: guideYAMLWithReleaseState("b", 2, "testing")},
+		"guides/mygroup/mychapter/c.yaml":       {Data: guideYAMLWithReleaseState("c", 3, "published")},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	want := map[string]ReleaseState{
+		"a": ReleaseStatePublished,
+		"b": ReleaseStateTesting,
+		"c": ReleaseStatePublished,
+	}
+	for slug, state := range want {
+		guide, _ := lib.GuideBySlug(slug)
+		if guide.ReleaseState != state {
+			t.Errorf("guide %s: expected release state %s, got %q", slug, state, guide.ReleaseState)
+		}
+	}
+}
+
+func TestReleaseState_Invalid(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: guideYAMLWithReleaseState("a", 1, "beta")},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 1 || problems[0].Code != CodeGuideReleaseStateInvalid || problems[0].Line != 1 {
+		t.Errorf("expected %s on line 1, got: %v", CodeGuideReleaseStateInvalid, problems)
+	}
+}
+
+func TestReleaseState_PublishedReferencesTesting(t *testing.T) {
+	recommending := []byte(`slug: c
+ordering: 3
+metadata:
+  title: "Guide c"
+` + validGuideMetadataYAML + `steps:
+  - order: 1
+    title: "Step 1"
+    instruction: "Do something"
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: ["b"]
+`)
+
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":             {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/a.yaml":       {Data: guideYAMLWithPrerequisites("a", 1, "b")},
+		"guides/mygroup/mychapter/b.yaml":       {Data: guideYAMLWithReleaseState("b", 2, "testing")},
+		"guides/mygroup/mychapter/c.yaml":       {Data: recommending},
+		"guides/mygroup/mychapter/d.yaml":       {Data: append([]byte("releaseState: testing\n"), guideYAMLWithPrerequisites("d", 4, "b")...)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got: %v", problems)
+	}
+	for _, p := range problems {
+		if p.Code != CodeReferenceNotPublished {
+			t.Errorf("expected %s, got: %v", CodeReferenceNotPublished, p)
+		}
+	}
+	if problems[0].Path != "guides/mygroup/mychapter/a.yaml" || problems[1].Path != "guides/mygroup/mychapter/c.yaml" {
+		t.Errorf("expected the problems in a.yaml and c.yaml, got: %v", problems)
+	}
+}
+
+func TestLibraryFilter(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":                   {Data: validGroupYAML()},
+		"guides/mygroup/mixed/chapter.yaml":           {Data: validChapterYAML(1)},
+		"guides/mygroup/mixed/a.yaml":                 {Data: validGuideYAML("a", 1)},
+		"guides/mygroup/mixed/b.yaml":                 {Data: guideYAMLWithReleaseState("b", 2, "testing")},
+		"guides/mygroup/testing-only/chapter.yaml":    {Data: validChapterYAML(2)},
+		"guides/mygroup/testing-only/c.yaml":          {Data: guideYAMLWithReleaseState("c", 1, "testing")},
+		"guides/othergroup/group.yaml":                {Data: validGroupYAML()},
+		"guides/othergroup/testing-only/chapter.yaml": {Data: validChapterYAML(1)},
+		"guides/othergroup/testing-only/d.yaml":       {Data: guideYAMLWithReleaseState("d", 1, "testing")},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	published := lib.Filter(ReleaseStatePublished)
+	if len(published.Groups) != 1 || len(published.Groups[0].Chapters) != 1 || len(published.Groups[0].Chapters[0].Guides) != 1 {
+		t.Fatalf("expected only mygroup/mixed/a to remain, got: %+v", published.Groups)
+	}
+	if _, ok := published.GuideBySlug("b"); ok {
+		t.Error("expected the testing guide b to be filtered out")
+	}
+	if _, ok := published.ChapterByPath("mygroup", "testing-only"); ok {
+		t.Error("expected the empty chapter to be dropped")
+	}
+	if _, ok := published.GroupBySlug("othergroup"); ok {
+		t.Error("expected the empty group to be dropped")
+	}
+
+	all := lib.Filter(ReleaseStatePublished, ReleaseStateTesting)
+	for _, slug := range []string{"a", "b", "c", "d"} {
+		if _, ok := all.GuideBySlug(slug); !ok {
+			t.Errorf("expected guide %s when including testing guides", slug)
+		}
+	}
+
+	if len(lib.Groups[0].Chapters[0].Guides) != 2 {
+		t.Error("expected Filter not to modify the original library")
+	}
+}
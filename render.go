@@ -0,0 +1,182 @@
+package userguides
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MissingVariableError reports a ${name} placeholder for which no value was
+// supplied.
+type MissingVariableError struct {
+	Name string
+	// Field is the first place the placeholder occurs, e.g.
+	// "steps[2].instruction" or "completion.successMessage".
+	Field string
+}
+
+func (e *MissingVariableError) Error() string {
+	return fmt.Sprintf("no value for variable %q used in %s", e.Name, e.Field)
+}
+
+// UnknownVariableError reports a value supplied for a variable that the
+// chapter does not declare.
+type UnknownVariableError struct {
+	Name    string
+	Chapter string
+}
+
+func (e *UnknownVariableError) Error() string {
+	return fmt.Sprintf("variable %q is not declared by chapter %s", e.Name, e.Chapter)
+}
+
+// placeholder is a ${name} reference found in a text field.
+type placeholder struct {
+	name       string
+	start, end int
+}
+
+// placeholderName matches the names that may appear in a placeholder. Other
+// "${...}" sequences, such as HCL interpolations like "${var.env}" inside
+// code samples, are left as literal text.
+var placeholderName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// scanPlaceholders returns the ${name} placeholders in s. "$${" is an escape
+// for a literal "${" and does not start a placeholder; an unterminated "${"
+// is left as literal text.
+func scanPlaceholders(s string) []placeholder {
+	var out []placeholder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			continue
+		}
+		if strings.HasPrefix(s[i:], "$${") {
+			i += 2
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			continue
+		}
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			break
+		}
+		if !placeholderName.MatchString(s[i+2 : i+2+end]) {
+			continue
+		}
+		out = append(out, placeholder{
+			name:  s[i+2 : i+2+end],
+			start: i,
+			end:   i + 2 + end + 1,
+		})
+		i += 2 + end
+	}
+	return out
+}
+
+// substitute replaces the placeholders in s with values and unescapes "$${".
+// Placeholders without a value are left in place and returned in missing.
+func substitute(s string, values map[string]string) (rendered string, missing []string) {
+	var b strings.Builder
+	last := 0
+	for _, p := range scanPlaceholders(s) {
+		b.WriteString(strings.ReplaceAll(s[last:p.start], "$${", "${"))
+		if v, ok := values[p.name]; ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(s[p.start:p.end])
+			missing = append(missing, p.name)
+		}
+		last = p.end
+	}
+	b.WriteString(strings.ReplaceAll(s[last:], "$${", "${"))
+	return b.String(), missing
+}
+
+// textFields calls fn with a pointer to every user-facing text field of
+// guide that may contain placeholders, along with the field's location in
+// the guide document.
+func textFields(guide *Guide, fn func(field []any, text *string)) {
+	for i := range guide.Steps {
+		step := &guide.Steps[i]
+		fn([]any{"steps", i, "title"}, &step.Title)
+		fn([]any{"steps", i, "instruction"}, &step.Instruction)
+		fn([]any{"steps", i, "hint"}, &step.Hint)
+		fn([]any{"steps", i, "validationHint"}, &step.ValidationHint)
+		for j := range step.Docs {
+			fn([]any{"steps", i, "docs", j, "title"}, &step.Docs[j].Title)
+		}
+	}
+	fn([]any{"completion", "successMessage"}, &guide.Completion.SuccessMessage)
+}
+
+// formatField renders a field location as, e.g., "steps[2].instruction".
+func formatField(field []any) string {
+	var b strings.Builder
+	for _, key := range field {
+		switch k := key.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(k)
+		case int:
+			fmt.Fprintf(&b, "[%d]", k)
+		}
+	}
+	return b.String()
+}
+
+// Render returns a copy of guide with the ${name} placeholders in step
+// titles, instructions, hints, validation hints and documentation titles
+// and in the success message replaced by values. Write "$${" for a literal "${".
+//
+// Every value must correspond to a variable declared by the chapter, and
+// every placeholder must have a value. Otherwise the error joins an
+// *UnknownVariableError or *MissingVariableError for each offending name,
+// and the returned guide has the offending placeholders left in place.
+func (c Chapter) Render(guide Guide, values map[string]string) (Guide, error) {
+	var errs []error
+
+	declared := make(map[string]bool, len(c.Variables))
+	for _, v := range c.Variables {
+		declared[v.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !declared[name] {
+			errs = append(errs, &UnknownVariableError{Name: name, Chapter: c.Slug})
+		}
+	}
+
+	guide.Steps = append([]GuideStep(nil), guide.Steps...)
+	for i := range guide.Steps {
+		guide.Steps[i].Docs = append([]GuideDoc(nil), guide.Steps[i].Docs...)
+	}
+	reported := make(map[string]bool)
+	textFields(&guide, func(field []any, text *string) {
+		rendered, missing := substitute(*text, values)
+		*text = rendered
+		for _, name := range missing {
+			if !reported[name] {
+				reported[name] = true
+				errs = append(errs, &MissingVariableError{Name: name, Field: formatField(field)})
+			}
+		}
+	})
+
+	return guide, errors.Join(errs...)
+}
+
+// Render renders the guide with the given slug using the variables declared
+// by its chapter. See Chapter.Render.
+func (l *Library) Render(guideSlug string, values map[string]string) (Guide, error) {
+	_, chapter, ok := l.ParentOf(guideSlug)
+	if !ok {
+		return Guide{}, fmt.Errorf("guide %s not found", guideSlug)
+	}
+	guide, _ := l.GuideBySlug(guideSlug)
+	return chapter.Render(*guide, values)
+}
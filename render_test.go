@@ -0,0 +1,121 @@
+package userguides_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+func renderChapter() userguides.Chapter {
+	return userguides.Chapter{
+		Slug: "test-chapter",
+		Variables: []userguides.GuideVariable{
+			{Name: "stack_name", ResourceType: userguides.VariableResourceTypeStack},
+			{Name: "policy_name", ResourceType: userguides.VariableResourceTypePolicy},
+		},
+	}
+}
+
+func renderGuide() userguides.Guide {
+	return userguides.Guide{
+		Slug: "test-guide",
+		Steps: []userguides.GuideStep{
+			{
+				Order:          1,
+				Title:          "Create ${stack_name}",
+				Instruction:    "Create **${stack_name}** and attach ${policy_name}.",
+				Hint:           "Use $${literal} for a literal, ${var.env} stays as is.",
+				ValidationHint: "Make sure ${stack_name} exists.",
+				Docs:           []userguides.GuideDoc{{Title: "Policies for ${stack_name}", URL: "https://docs.spacelift.io/concepts/policy"}},
+			},
+		},
+		Completion: userguides.GuideCompletion{
+			SuccessMessage: "${stack_name} is protected by ${policy_name}.",
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	guide := renderGuide()
+
+	rendered, err := renderChapter().Render(guide, map[string]string{
+		"stack_name":  "kind-cat",
+		"policy_name": "no-deletes",
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	step := rendered.Steps[0]
+	checks := []struct{ field, got, want string }{
+		{"instruction", step.Instruction, "Create **kind-cat** and attach no-deletes."},
+		{"hint", step.Hint, "Use ${literal} for a literal, ${var.env} stays as is."},
+		{"validationHint", step.ValidationHint, "Make sure kind-cat exists."},
+		{"successMessage", rendered.Completion.SuccessMessage, "kind-cat is protected by no-deletes."},
+		{"title", step.Title, "Create kind-cat"},
+		{"docs[0].title", step.Docs[0].Title, "Policies for kind-cat"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.field, c.got, c.want)
+		}
+	}
+
+	if guide.Steps[0].Instruction != renderGuide().Steps[0].Instruction || guide.Steps[0].Docs[0].Title != renderGuide().Steps[0].Docs[0].Title {
+		t.Error("Render modified the steps of the guide passed in")
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	rendered, err := renderChapter().Render(renderGuide(), map[string]string{
+		"stack_name": "kind-cat",
+		"stak_name":  "typo",
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var unknown *userguides.UnknownVariableError
+	if !errors.As(err, &unknown) || unknown.Name != "stak_name" {
+		t.Errorf("expected an UnknownVariableError for stak_name, got: %v", err)
+	}
+
+	var missing *userguides.MissingVariableError
+	if !errors.As(err, &missing) || missing.Name != "policy_name" || missing.Field != "steps[0].instruction" {
+		t.Errorf("expected a MissingVariableError for policy_name in steps[0].instruction, got: %v", err)
+	}
+
+	if got := len(strings.Split(err.Error(), "\n")); got != 2 {
+		t.Errorf("expected each name to be reported once, got:\n%v", err)
+	}
+
+	if want := "Create **kind-cat** and attach ${policy_name}."; rendered.Steps[0].Instruction != want {
+		t.Errorf("instruction = %q, want %q", rendered.Steps[0].Instruction, want)
+	}
+}
+
+func TestLibraryRender(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	rendered, err := lib.Render("ground-control-first-stack", map[string]string{
+		"main_stack_name": "kind-cat",
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	for _, step := range rendered.Steps {
+		if strings.Contains(step.Instruction, "${main_stack_name}") {
+			t.Errorf("step %d still contains a placeholder: %s", step.Order, step.Instruction)
+		}
+	}
+
+	if _, err := lib.Render("no-such-guide", nil); err == nil {
+		t.Error("expected error for an unknown guide, got nil")
+	}
+}
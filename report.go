@@ -0,0 +1,260 @@
+package userguides
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity tells whether a Problem makes the library unusable.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// RuleCode identifies the check that produced a Problem. Codes are stable
+// across releases and safe to match on or to suppress in tooling.
+type RuleCode string
+
+const (
+	CodeFileUnreadable RuleCode = "file-unreadable"
+	CodeYAMLSyntax     RuleCode = "yaml-syntax"
+	CodeYAMLType       RuleCode = "yaml-type"
+
+	CodeSchemaViolation RuleCode = "schema-violation"
+	CodeFieldUnknown    RuleCode = "field-unknown"
+
+	CodeGroupNameRequired       RuleCode = "group-name-required"
+	CodeGroupSkillLevelRequired RuleCode = "group-skill-level-required"
+	CodeGroupSkillLevelInvalid  RuleCode = "group-skill-level-invalid"
+	CodeGroupSlugDuplicate      RuleCode = "group-slug-duplicate"
+
+	CodeChapterNameRequired          RuleCode = "chapter-name-required"
+	CodeChapterSlugDuplicate         RuleCode = "chapter-slug-duplicate"
+	CodeChapterOrderingDuplicate     RuleCode = "chapter-ordering-duplicate"
+	CodeVariableResourceTypeRequired RuleCode = "variable-resource-type-required"
+	CodeVariableResourceTypeInvalid  RuleCode = "variable-resource-type-invalid"
+	CodeVariableUndeclared           RuleCode = "variable-undeclared"
+	CodeVariableUnused               RuleCode = "variable-unused"
+	CodeExpectationUndeclared        RuleCode = "expectation-undeclared"
+
+	CodeGuideSlugRequired        RuleCode = "guide-slug-required"
+	CodeGuideSlugDuplicate       RuleCode = "guide-slug-duplicate"
+	CodeGuideOrderingDuplicate   RuleCode = "guide-ordering-duplicate"
+	CodeGuideTitleRequired       RuleCode = "guide-title-required"
+	CodeGuideStepsRequired       RuleCode = "guide-steps-required"
+	CodeGuideDifficultyRequired  RuleCode = "guide-difficulty-required"
+	CodeGuideDifficultyInvalid   RuleCode = "guide-difficulty-invalid"
+	CodeGuideLabelsRequired      RuleCode = "guide-labels-required"
+	CodeGuideLabelEmpty          RuleCode = "guide-label-empty"
+	CodeGuideMinutesInvalid      RuleCode = "guide-minutes-invalid"
+	CodeGuideReleaseStateInvalid RuleCode = "guide-release-state-invalid"
+	CodeRecommendedGuideNotFound RuleCode = "recommended-guide-not-found"
+	CodeReferenceNotPublished    RuleCode = "reference-not-published"
+
+	CodePrerequisiteNotFound RuleCode = "prerequisite-not-found"
+	CodePrerequisiteSelf     RuleCode = "prerequisite-self"
+	CodePrerequisiteCycle    RuleCode = "prerequisite-cycle"
+
+	CodeStepOrderInvalid        RuleCode = "step-order-invalid"
+	CodeStepOrderDuplicate      RuleCode = "step-order-duplicate"
+	CodeStepOrderNotSequential  RuleCode = "step-order-not-sequential"
+	CodeStepTitleRequired       RuleCode = "step-title-required"
+	CodeStepInstructionRequired RuleCode = "step-instruction-required"
+	CodeDocTitleRequired        RuleCode = "doc-title-required"
+	CodeDocURLRequired          RuleCode = "doc-url-required"
+	CodeDocURLMalformed         RuleCode = "doc-url-malformed"
+	CodeDocURLScheme            RuleCode = "doc-url-scheme"
+
+	CodeValidationSyntax      RuleCode = "validation-syntax"
+	CodeValidationPackage     RuleCode = "validation-package"
+	CodeValidationRuleMissing RuleCode = "validation-rule-missing"
+	CodeValidationCompile     RuleCode = "validation-compile"
+	CodeValidationType        RuleCode = "validation-type"
+)
+
+// Problem is a single finding produced while validating a library.
+type Problem struct {
+	// Path is the file the problem was found in, relative to the root of
+	// the loaded file system. It is empty for problems reported by the
+	// Validate methods, which have no file to point at.
+	Path string
+	// Line and Column locate the offending YAML node, starting at 1. They
+	// are zero when the position is unknown.
+	Line     int
+	Column   int
+	Severity Severity
+	Code     RuleCode
+	Message  string
+
+	// field is the location of the problem within its document, as a
+	// sequence of mapping keys (string) and sequence indexes (int). The
+	// loader resolves it to Line and Column.
+	field []any
+}
+
+func (p Problem) Error() string {
+	var b strings.Builder
+	if p.Path != "" {
+		b.WriteString(p.Path)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+		}
+		if p.Column > 0 {
+			fmt.Fprintf(&b, ":%d", p.Column)
+		}
+		b.WriteString(": ")
+	}
+	if p.Severity == SeverityWarning {
+		b.WriteString("warning: ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationReport collects every problem found while validating a library,
+// rather than stopping at the first one.
+//
+// A report with at least one error-severity problem is returned as the error
+// from Load and the Validate methods. It unwraps to its problems, so
+// errors.As can be used to inspect individual entries.
+type ValidationReport struct {
+	Problems []Problem
+}
+
+func (r *ValidationReport) addf(severity Severity, code RuleCode, field []any, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		field:    field,
+	})
+}
+
+func (r *ValidationReport) errorf(code RuleCode, field []any, format string, args ...any) {
+	r.addf(SeverityError, code, field, format, args...)
+}
+
+// HasErrors reports whether any problem has error severity.
+func (r *ValidationReport) HasErrors() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the report as an error if it contains errors, and nil
+// otherwise.
+func (r *ValidationReport) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	return r
+}
+
+// Error lists every problem on its own line, like errors.Join.
+func (r *ValidationReport) Error() string {
+	lines := make([]string, len(r.Problems))
+	for i, p := range r.Problems {
+		lines[i] = p.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *ValidationReport) Unwrap() []error {
+	errs := make([]error, len(r.Problems))
+	for i, p := range r.Problems {
+		errs[i] = p
+	}
+	return errs
+}
+
+// attach copies the problems in src to r, setting their file path and
+// resolving their position within doc.
+func (r *ValidationReport) attach(src *ValidationReport, filePath string, doc *yaml.Node) {
+	for _, p := range src.Problems {
+		p.Path = filePath
+		if doc != nil {
+			p.Line, p.Column = locate(doc, p.field)
+		}
+		r.Problems = append(r.Problems, p)
+	}
+}
+
+// textOffset, as the last element of a field, points at a byte offset
+// within a scalar value rather than at the scalar itself.
+type textOffset int
+
+// mappingKey, as the last element of a field, points at the key of the
+// preceding mapping entry rather than at its value.
+type mappingKey struct{}
+
+// scalarPosition returns the position of the byte at offset within the value
+// of a scalar node. Block scalars start on the line after their indicator;
+// their column is not tracked by yaml.v3 and is reported as zero.
+func scalarPosition(node *yaml.Node, offset int) (line, column int) {
+	before := node.Value[:offset]
+	switch node.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		return node.Line + 1 + strings.Count(before, "\n"), 0
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		if !strings.Contains(before, "\n") {
+			return node.Line, node.Column + 1 + offset
+		}
+	default:
+		if !strings.Contains(before, "\n") {
+			return node.Line, node.Column + offset
+		}
+	}
+	return node.Line, node.Column
+}
+
+// locate returns the position of the node at field within doc. When the
+// field is absent it falls back to the closest ancestor that exists.
+func locate(doc *yaml.Node, field []any) (line, column int) {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, column = node.Line, node.Column
+
+	var key *yaml.Node
+	for _, step := range field {
+		var next *yaml.Node
+		switch k := step.(type) {
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == k {
+						key, next = node.Content[i], node.Content[i+1]
+						break
+					}
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && k >= 0 && k < len(node.Content) {
+				key, next = nil, node.Content[k]
+			}
+		case textOffset:
+			if node.Kind == yaml.ScalarNode && int(k) <= len(node.Value) {
+				return scalarPosition(node, int(k))
+			}
+		case mappingKey:
+			if key != nil {
+				return key.Line, key.Column
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+		line, column = node.Line, node.Column
+	}
+
+	return line, column
+}
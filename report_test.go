@@ -0,0 +1,118 @@
+package userguides
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidationReport_CollectsAllProblems(t *testing.T) {
+	badGuide := `slug: guide-two
+ordering: 1
+metadata:
+  title: ""
+  difficulty: "impossible"
+  description: "test"
+  labels: ["test"]
+  minutesToComplete: 5
+  prerequisites: []
+steps:
+  - order: 1
+    title: "Step"
+    instruction: "Do this"
+    docs:
+      - title: "Docs"
+        url: "ftp://example.com"
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: ["missing-guide"]
+`
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: []byte("name: \"\"\ndescription: \"test\"\nskillLevel: NOVICE\nordering: 1\n")},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: validGuideYAML("guide-one", 1)},
+		"guides/mygroup/mychapter/guide-two.yaml": {Data: []byte(badGuide)},
+		"guides/mygroup/mychapter/broken.yaml":    {Data: []byte("slug: broken\nsteps: [\n")},
+	}
+
+	_, err := Load(f)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var report *ValidationReport
+	if !errors.As(err, &report) {
+		t.Fatalf("expected a *ValidationReport, got %T", err)
+	}
+
+	want := []struct {
+		path   string
+		code   RuleCode
+		line   int
+		column int
+	}{
+		{"guides/mygroup/group.yaml", CodeGroupNameRequired, 1, 7},
+		{"guides/mygroup/group.yaml", CodeGroupSkillLevelInvalid, 3, 13},
+		{"guides/mygroup/mychapter/broken.yaml", CodeYAMLSyntax, 2, 0},
+		{"guides/mygroup/mychapter/guide-two.yaml", CodeGuideTitleRequired, 4, 10},
+		{"guides/mygroup/mychapter/guide-two.yaml", CodeGuideDifficultyInvalid, 5, 15},
+		{"guides/mygroup/mychapter/guide-two.yaml", CodeDocURLScheme, 16, 14},
+		{"guides/mygroup/mychapter/guide-two.yaml", CodeGuideOrderingDuplicate, 2, 11},
+		{"guides/mygroup/mychapter/guide-two.yaml", CodeRecommendedGuideNotFound, 19, 25},
+	}
+
+	if len(report.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d:\n%v", len(want), len(report.Problems), report)
+	}
+
+	for i, w := range want {
+		p := report.Problems[i]
+		if p.Path != w.path || p.Code != w.code || p.Line != w.line || p.Column != w.column {
+			t.Errorf("problem %d: expected %s:%d:%d [%s], got %s:%d:%d [%s] %s", i, w.path, w.line, w.column, w.code, p.Path, p.Line, p.Column, p.Code, p.Message)
+		}
+		if p.Severity != SeverityError {
+			t.Errorf("problem %d: expected error severity, got %s", i, p.Severity)
+		}
+	}
+}
+
+func TestValidationReport_Unwrap(t *testing.T) {
+	guide := Guide{
+		Slug:     "test-guide",
+		Metadata: GuideMetadata{Labels: []string{""}, Difficulty: "easy", MinutesToComplete: 5},
+	}
+
+	err := guide.Validate()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var problem Problem
+	if !errors.As(err, &problem) {
+		t.Fatalf("expected error to unwrap to a Problem, got %T", err)
+	}
+	if problem.Code != CodeGuideTitleRequired {
+		t.Errorf("expected first problem to be %s, got %s", CodeGuideTitleRequired, problem.Code)
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 {
+		t.Errorf("expected one line per problem, got:\n%v", err)
+	}
+}
+
+func TestValidationReport_WarningsAreNotErrors(t *testing.T) {
+	var r ValidationReport
+	r.addf(SeverityWarning, CodeGuideLabelEmpty, nil, "just a warning")
+
+	if r.HasErrors() {
+		t.Error("expected HasErrors to be false for a warning-only report")
+	}
+	if r.Err() != nil {
+		t.Errorf("expected Err to be nil for a warning-only report, got: %v", r.Err())
+	}
+	if !strings.HasPrefix(r.Error(), "warning: ") {
+		t.Errorf("expected warning prefix, got %q", r.Error())
+	}
+}
@@ -0,0 +1,199 @@
+package userguides
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+)
+
+// documentSchemas compiles the document schemas once, keyed by $id.
+var documentSchemas = sync.OnceValues(func() (map[string]*jsonschema.Schema, error) {
+	sources := map[string]func() ([]byte, error){
+		groupSchema:   groupDocumentSchema,
+		chapterSchema: chapterDocumentSchema,
+		guideSchema:   guideDocumentSchema,
+	}
+
+	c := jsonschema.NewCompiler()
+	for id, source := range sources {
+		data, err := source()
+		if err != nil {
+			return nil, fmt.Errorf("generate %s: %w", id, err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", id, err)
+		}
+		if err := c.AddResource(id, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	schemas := make(map[string]*jsonschema.Schema, len(sources))
+	for id := range sources {
+		s, err := c.Compile(id)
+		if err != nil {
+			return nil, fmt.Errorf("compile %s: %w", id, err)
+		}
+		schemas[id] = s
+	}
+	return schemas, nil
+})
+
+var schemaPrinter = message.NewPrinter(language.English)
+
+// checkSchema validates the decoded file name against the schema with the
+// given $id.
+// Violations at a field that already has a problem in the file are left
+// out, since the loader's own checks describe them better.
+func (l *loader) checkSchema(name, schemaID string) {
+	doc := l.docs[name]
+	if doc == nil || len(doc.Content) == 0 {
+		return
+	}
+
+	// See generateSchema for why a failure is reported, not a panic.
+	schemas, err := documentSchemas()
+	if err != nil {
+		l.fail(name, nil, CodeSchemaViolation, "cannot check the file against %s: %v", schemaID, err)
+		return
+	}
+
+	instance, err := jsonValue(doc)
+	if err != nil {
+		l.fail(name, nil, CodeSchemaViolation, "%v", err)
+		return
+	}
+
+	err = schemas[schemaID].Validate(instance)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return
+	}
+
+	reported := make(map[string]bool)
+	for _, p := range l.report.Problems {
+		if p.Path == name {
+			reported[fieldKey(p.field)] = true
+		}
+	}
+
+	l.check(name, func(r *ValidationReport) {
+		for _, v := range schemaViolations(verr, instance) {
+			if !reported[fieldKey(v.field)] {
+				reported[fieldKey(v.field)] = true
+				r.errorf(CodeSchemaViolation, v.field, "%s", v.message)
+			}
+		}
+	})
+}
+
+// jsonValue converts a YAML document into the JSON value the schema
+// validator expects.
+func jsonValue(doc *yaml.Node) (any, error) {
+	var v any
+	if err := doc.Decode(&v); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+type schemaViolation struct {
+	field   []any
+	message string
+}
+
+// schemaViolations flattens a validation error into its leaf causes. A
+// missing or unexpected property is reported at the property itself, one
+// violation per property; an unexpected one at its key.
+func schemaViolations(err *jsonschema.ValidationError, instance any) []schemaViolation {
+	if len(err.Causes) > 0 {
+		var out []schemaViolation
+		for _, cause := range err.Causes {
+			out = append(out, schemaViolations(cause, instance)...)
+		}
+		return out
+	}
+
+	field := instanceField(err.InstanceLocation, instance)
+	switch k := err.ErrorKind.(type) {
+	case *kind.Required:
+		out := make([]schemaViolation, len(k.Missing))
+		for i, prop := range k.Missing {
+			out[i] = schemaViolation{
+				field:   append(slices.Clone(field), prop),
+				message: atField(field, (&kind.Required{Missing: []string{prop}}).LocalizedString(schemaPrinter)),
+			}
+		}
+		return out
+	case *kind.AdditionalProperties:
+		out := make([]schemaViolation, len(k.Properties))
+		for i, prop := range k.Properties {
+			out[i] = schemaViolation{
+				field:   append(slices.Clone(field), prop, mappingKey{}),
+				message: atField(field, (&kind.AdditionalProperties{Properties: []string{prop}}).LocalizedString(schemaPrinter)),
+			}
+		}
+		return out
+	default:
+		return []schemaViolation{{field: field, message: atField(field, err.ErrorKind.LocalizedString(schemaPrinter))}}
+	}
+}
+
+// atField prefixes msg with the field it applies to, if any.
+func atField(field []any, msg string) string {
+	if len(field) == 0 {
+		return msg
+	}
+	return formatField(field) + ": " + msg
+}
+
+// instanceField converts a JSON pointer into a field path, turning the
+// tokens that index arrays into ints.
+func instanceField(location []string, instance any) []any {
+	field := make([]any, 0, len(location))
+	v := instance
+	for _, token := range location {
+		switch node := v.(type) {
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return append(field, token)
+			}
+			field = append(field, i)
+			v = node[i]
+		case map[string]any:
+			field = append(field, token)
+			v = node[token]
+		default:
+			return append(field, token)
+		}
+	}
+	return field
+}
+
+// fieldKey identifies a field path, ignoring any offset into a scalar and
+// whether the key or the value of a mapping entry is meant.
+func fieldKey(field []any) string {
+	if n := len(field); n > 0 {
+		switch field[n-1].(type) {
+		case textOffset, mappingKey:
+			field = field[:n-1]
+		}
+	}
+	return fmt.Sprintf("%#v", field)
+}
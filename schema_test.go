@@ -0,0 +1,71 @@
+package userguides
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSchema_ConstraintViolation(t *testing.T) {
+	guide := strings.Replace(string(validGuideYAML("guide-one", 1)), "slug: guide-one", "slug: Guide_One", 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(0)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got: %v", problems)
+	}
+
+	want := []struct {
+		path string
+		line int
+		text string
+	}{
+		{"guides/mygroup/mychapter/chapter.yaml", 3, "ordering"},
+		{"guides/mygroup/mychapter/guide-one.yaml", 1, "slug"},
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.Code != CodeSchemaViolation || p.Path != w.path || p.Line != w.line {
+			t.Errorf("expected %s at %s:%d, got: %v", CodeSchemaViolation, w.path, w.line, p)
+		}
+		if !strings.Contains(p.Message, w.text) {
+			t.Errorf("expected the message to mention %q, got: %s", w.text, p.Message)
+		}
+	}
+}
+
+func TestSchema_MissingRequiredField(t *testing.T) {
+	guide := strings.Replace(string(validGuideYAML("guide-one", 1)), "  description: \"test\"\n", "", 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 1 {
+		t.Fatalf("expected 1 problem, got: %v", problems)
+	}
+	p := problems[0]
+	if p.Code != CodeSchemaViolation || p.Line != 4 || !strings.Contains(p.Message, "metadata: missing property 'description'") {
+		t.Errorf("expected the missing description to be reported at the metadata on line 4, got: %v", p)
+	}
+}
+
+func TestSchema_DuplicatesOfLoaderChecksAreSkipped(t *testing.T) {
+	guide := strings.Replace(string(validGuideYAML("guide-one", 1)), "difficulty: \"easy\"", "difficulty: \"impossible\"", 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: validChapterYAML(1)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 1 || problems[0].Code != CodeGuideDifficultyInvalid {
+		t.Errorf("expected only %s, got: %v", CodeGuideDifficultyInvalid, problems)
+	}
+}
@@ -0,0 +1,241 @@
+// Package server serves a library over HTTP as read-only JSON, in the
+// format of the export package, so the frontend can be developed against
+// guide content without running the backend.
+//
+// Routes:
+//
+//	GET /groups                        every group, as an export.Document
+//	GET /groups/{slug}                 one group, as an export.Group
+//	GET /guides/{slug}                 one guide, as an export.Guide
+//	GET /guides/{slug}/steps/{order}   one step, as an export.Step
+//
+// Query parameters of the guide and step routes are chapter variables: with
+// ?main_stack_name=prod, ${main_stack_name} is rendered as prod. Placeholders
+// without a value are left in place, and a parameter the chapter does not
+// declare is a 400 error.
+//
+// Only published guides are served. With WithPreview, the same routes under
+// /preview serve guides under test as well.
+//
+// Every response carries an ETag derived from a hash of its content, and is
+// gzip-compressed for clients that accept it.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+)
+
+// Server is an http.Handler serving a library.
+type Server struct {
+	mux     *http.ServeMux
+	preview bool
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPreview serves the whole library, guides under test included, under
+// /preview.
+func WithPreview() Option {
+	return func(s *Server) {
+		s.preview = true
+	}
+}
+
+// New returns a server for lib, which must have been returned by
+// userguides.Load or Guides.
+func New(lib *userguides.Library, opts ...Option) *Server {
+	s := &Server{mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.handle("", newContent(lib.Filter(userguides.ReleaseStatePublished)))
+	if s.preview {
+		s.handle("/preview", newContent(lib))
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// content is the library served under one prefix, and its export.
+type content struct {
+	lib    *userguides.Library
+	doc    *export.Document
+	groups map[string]*export.Group
+	guides map[string]*export.Guide
+}
+
+func newContent(lib *userguides.Library) *content {
+	c := &content{
+		lib:    lib,
+		doc:    export.New(lib),
+		groups: make(map[string]*export.Group),
+		guides: make(map[string]*export.Guide),
+	}
+	for i := range c.doc.Groups {
+		group := &c.doc.Groups[i]
+		c.groups[group.Slug] = group
+		for j := range group.Chapters {
+			chapter := &group.Chapters[j]
+			for k := range chapter.Guides {
+				c.guides[chapter.Guides[k].Slug] = &chapter.Guides[k]
+			}
+		}
+	}
+	return c
+}
+
+func (s *Server) handle(prefix string, c *content) {
+	s.mux.HandleFunc("GET "+prefix+"/groups", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, c.doc)
+	})
+
+	s.mux.HandleFunc("GET "+prefix+"/groups/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		group, ok := c.groups[r.PathValue("slug")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "group %s not found", r.PathValue("slug"))
+			return
+		}
+		writeJSON(w, r, group)
+	})
+
+	s.mux.HandleFunc("GET "+prefix+"/guides/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if guide, ok := c.guide(w, r); ok {
+			writeJSON(w, r, guide)
+		}
+	})
+
+	s.mux.HandleFunc("GET "+prefix+"/guides/{slug}/steps/{order}", func(w http.ResponseWriter, r *http.Request) {
+		guide, ok := c.guide(w, r)
+		if !ok {
+			return
+		}
+		order, err := strconv.Atoi(r.PathValue("order"))
+		if err != nil || order < 1 || order > len(guide.Steps) {
+			writeError(w, http.StatusNotFound, "guide %s has no step %s", guide.Slug, r.PathValue("order"))
+			return
+		}
+		// Steps are numbered from 1 without gaps.
+		writeJSON(w, r, guide.Steps[order-1])
+	})
+}
+
+// guide returns the guide named by the request, rendered with the
+// variables in its query. It writes the error response and returns false if
+// there is no such guide or a variable is not declared.
+func (c *content) guide(w http.ResponseWriter, r *http.Request) (*export.Guide, bool) {
+	slug := r.PathValue("slug")
+	guide, ok := c.guides[slug]
+	if !ok {
+		writeError(w, http.StatusNotFound, "guide %s not found", slug)
+		return nil, false
+	}
+
+	query := r.URL.Query()
+	if len(query) == 0 {
+		return guide, true
+	}
+
+	values := make(map[string]string, len(query))
+	for name := range query {
+		values[name] = query.Get(name)
+	}
+	// Missing values leave their placeholders in place, so that a preview
+	// can be rendered with only some of the variables.
+	rendered, err := c.lib.Render(slug, values)
+	var unknown *userguides.UnknownVariableError
+	if errors.As(err, &unknown) {
+		writeError(w, http.StatusBadRequest, "%v", unknown)
+		return nil, false
+	}
+
+	exported := export.NewGuide(rendered)
+	return &exported, true
+}
+
+// writeJSON writes v as the response, or a 304 if the client's copy, named
+// by If-None-Match, is current.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	// The ETag is weak because the gzip and identity encodings share it.
+	sum := sha256.Sum256(body.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Vary", "Accept-Encoding")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.Set("Content-Type", "application/json")
+	if !acceptsGzip(r) {
+		h.Set("Content-Length", strconv.Itoa(body.Len()))
+		w.Write(body.Bytes())
+		return
+	}
+
+	h.Set("Content-Encoding", "gzip")
+	gz := gzip.NewWriter(w)
+	gz.Write(body.Bytes())
+	gz.Close()
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, using
+// the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		if strings.TrimSpace(name) == "gzip" {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// errorResponse is the body of error responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: fmt.Sprintf(format, args...)})
+}
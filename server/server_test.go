@@ -0,0 +1,191 @@
+package server_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/export"
+	"github.com/spacelift-io/spacelift-user-guides-library/server"
+)
+
+// testServer serves testdata/guides: the published guide first-stack and
+// enable-drift, which is under test.
+func testServer(t *testing.T, opts ...server.Option) *server.Server {
+	t.Helper()
+
+	lib, err := userguides.Load(os.DirFS("testdata"))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	return server.New(lib, opts...)
+}
+
+func get(t *testing.T, h http.Handler, target string, header http.Header) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func decode(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("expected a JSON body: %v", err)
+	}
+}
+
+func TestServer_Routes(t *testing.T) {
+	srv := testServer(t)
+
+	tests := []struct {
+		target string
+		status int
+		want   string
+	}{
+		{"/groups", http.StatusOK, `"formatVersion":1`},
+		{"/groups/basics", http.StatusOK, `"slug":"intro"`},
+		{"/guides/first-stack", http.StatusOK, `"instruction":"Create ${stack_name} in ${space_name}"`},
+		{"/guides/first-stack/steps/2", http.StatusOK, `"title":"Run"`},
+		{"/groups/missing", http.StatusNotFound, `{"error":"group missing not found"}`},
+		{"/guides/missing", http.StatusNotFound, `{"error":"guide missing not found"}`},
+		{"/guides/first-stack/steps/3", http.StatusNotFound, `{"error":"guide first-stack has no step 3"}`},
+		{"/guides/first-stack/steps/first", http.StatusNotFound, `{"error":"guide first-stack has no step first"}`},
+		// Guides under test are not served outside of the preview.
+		{"/groups/advanced", http.StatusNotFound, `{"error":"group advanced not found"}`},
+		{"/guides/enable-drift", http.StatusNotFound, `{"error":"guide enable-drift not found"}`},
+		{"/preview/guides/enable-drift", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			resp := get(t, srv, tt.target, nil)
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status || !strings.Contains(string(body), tt.want) {
+				t.Errorf("expected %d with a body containing %s, got %d: %s", tt.status, tt.want, resp.StatusCode, body)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" && tt.want != "" {
+				t.Errorf("expected a JSON response, got %s", ct)
+			}
+		})
+	}
+
+	var doc export.Document
+	decode(t, get(t, srv, "/groups", nil), &doc)
+	if len(doc.Groups) != 1 || doc.Groups[0].Slug != "basics" {
+		t.Errorf("expected only the basics group, got %+v", doc.Groups)
+	}
+}
+
+func TestServer_Render(t *testing.T) {
+	srv := testServer(t)
+
+	resp := get(t, srv, "/guides/first-stack?stack_name=prod&space_name=root", nil)
+	var guide export.Guide
+	decode(t, resp, &guide)
+	if got, want := guide.Steps[0].Instruction, "Create prod in root"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got, want := guide.Completion.SuccessMessage, "prod is ready"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// Placeholders without a value are left in place.
+	resp = get(t, srv, "/guides/first-stack/steps/1?stack_name=prod", nil)
+	var step export.Step
+	decode(t, resp, &step)
+	if got, want := step.Instruction, "Create prod in ${space_name}"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	resp = get(t, srv, "/guides/first-stack?stack=prod", nil)
+	body, _ := io.ReadAll(resp.Body)
+	if want := `variable \"stack\" is not declared by chapter intro`; resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), want) {
+		t.Errorf("expected a 400 error containing %s, got %d: %s", want, resp.StatusCode, body)
+	}
+}
+
+func TestServer_ETag(t *testing.T) {
+	srv := testServer(t)
+
+	resp := get(t, srv, "/guides/first-stack", nil)
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	resp = get(t, srv, "/guides/first-stack", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("expected %d for a current ETag, got %d", http.StatusNotModified, resp.StatusCode)
+	}
+
+	// The ETag follows the content, including the rendered variables.
+	resp = get(t, srv, "/guides/first-stack?stack_name=prod", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("expected a new ETag for different content, got %d with %s", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
+
+func TestServer_Gzip(t *testing.T) {
+	srv := testServer(t)
+
+	plain := get(t, srv, "/groups", nil)
+	want, _ := io.ReadAll(plain.Body)
+
+	resp := get(t, srv, "/groups", http.Header{"Accept-Encoding": {"br, gzip"}})
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip-compressed response, got headers %v", resp.Header)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("expected the decompressed body to match the plain one, got:\n%s", got)
+	}
+	if resp.Header.Get("ETag") != plain.Header.Get("ETag") {
+		t.Errorf("expected both encodings to share the ETag")
+	}
+
+	if resp := get(t, srv, "/groups", http.Header{"Accept-Encoding": {"gzip;q=0"}}); resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("expected gzip;q=0 to disable compression")
+	}
+}
+
+func TestServer_Preview(t *testing.T) {
+	srv := testServer(t, server.WithPreview())
+
+	resp := get(t, srv, "/preview/groups", nil)
+	var doc export.Document
+	decode(t, resp, &doc)
+	if len(doc.Groups) != 2 {
+		t.Errorf("expected the preview to include the group under test, got %+v", doc.Groups)
+	}
+
+	resp = get(t, srv, "/preview/guides/enable-drift/steps/1?stack_name=prod&space_name=root", nil)
+	var step export.Step
+	decode(t, resp, &step)
+	if step.Instruction != "Create prod in root" {
+		t.Errorf("expected the preview to render guides under test, got %q", step.Instruction)
+	}
+
+	if resp := get(t, srv, "/guides/enable-drift", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected guides under test to stay hidden outside of the preview, got %d", resp.StatusCode)
+	}
+}
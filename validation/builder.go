@@ -0,0 +1,361 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// StackSource lists the stacks in an account.
+type StackSource interface {
+	ListStacks(ctx context.Context) ([]userguides.InputStack, error)
+}
+
+// RunSource lists the runs of the stacks in an account.
+type RunSource interface {
+	ListRuns(ctx context.Context) ([]userguides.InputRun, error)
+}
+
+// AWSIntegrationSource lists the AWS integrations in an account and their
+// attachments to stacks.
+type AWSIntegrationSource interface {
+	ListAWSIntegrations(ctx context.Context) ([]userguides.InputAWSIntegration, error)
+	ListAWSAttachments(ctx context.Context) ([]userguides.InputAWSAttachment, error)
+}
+
+// PolicySource lists the policies in an account and their attachments to
+// stacks.
+type PolicySource interface {
+	ListPolicies(ctx context.Context) ([]userguides.InputPolicy, error)
+	ListPolicyAttachments(ctx context.Context) ([]userguides.InputPolicyAttachment, error)
+}
+
+// ContextSource lists the contexts in an account and their attachments to
+// stacks.
+type ContextSource interface {
+	ListContexts(ctx context.Context) ([]userguides.InputContext, error)
+	ListContextAttachments(ctx context.Context) ([]userguides.InputContextAttachment, error)
+}
+
+// SpaceSource lists the spaces in an account.
+type SpaceSource interface {
+	ListSpaces(ctx context.Context) ([]userguides.InputSpace, error)
+}
+
+// StackDependencySource lists the dependencies between the stacks in an
+// account and the outputs they pass to each other.
+type StackDependencySource interface {
+	ListStackDependencies(ctx context.Context) ([]userguides.InputStackDependency, error)
+	ListStackDependencyReferences(ctx context.Context) ([]userguides.InputStackDependencyReference, error)
+}
+
+// AccountSource provides every resource a validation input can hold.
+// Snapshot implements it.
+type AccountSource interface {
+	StackSource
+	RunSource
+	AWSIntegrationSource
+	PolicySource
+	ContextSource
+	SpaceSource
+	StackDependencySource
+}
+
+// ValidationInputBuilder assembles the input document step validations are
+// evaluated against from the configured sources.
+type ValidationInputBuilder struct {
+	stacks       StackSource
+	runs         RunSource
+	aws          AWSIntegrationSource
+	policies     PolicySource
+	contexts     ContextSource
+	spaces       SpaceSource
+	dependencies StackDependencySource
+}
+
+// BuilderOption configures a ValidationInputBuilder.
+type BuilderOption func(*ValidationInputBuilder)
+
+// WithStackSource sets the source of input.stacks.
+func WithStackSource(s StackSource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.stacks = s }
+}
+
+// WithRunSource sets the source of input.runs.
+func WithRunSource(s RunSource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.runs = s }
+}
+
+// WithAWSIntegrationSource sets the source of input.aws_integrations and
+// input.aws_attachments.
+func WithAWSIntegrationSource(s AWSIntegrationSource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.aws = s }
+}
+
+// WithPolicySource sets the source of input.policies and
+// input.policy_attachments.
+func WithPolicySource(s PolicySource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.policies = s }
+}
+
+// WithContextSource sets the source of input.contexts and
+// input.context_attachments.
+func WithContextSource(s ContextSource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.contexts = s }
+}
+
+// WithSpaceSource sets the source of input.spaces.
+func WithSpaceSource(s SpaceSource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.spaces = s }
+}
+
+// WithStackDependencySource sets the source of input.stack_dependencies and
+// input.stack_dependency_references.
+func WithStackDependencySource(s StackDependencySource) BuilderOption {
+	return func(b *ValidationInputBuilder) { b.dependencies = s }
+}
+
+// WithAccountSource sets every source at once.
+func WithAccountSource(s AccountSource) BuilderOption {
+	return func(b *ValidationInputBuilder) {
+		b.stacks, b.runs, b.aws, b.policies, b.contexts, b.spaces, b.dependencies = s, s, s, s, s, s, s
+	}
+}
+
+// NewValidationInputBuilder returns a builder using the given sources.
+// Collections without a source are left out of the input document; those
+// with one are always present, as [] if the source lists nothing.
+func NewValidationInputBuilder(opts ...BuilderOption) *ValidationInputBuilder {
+	b := &ValidationInputBuilder{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Build returns the input document for the guides of chapter. values holds
+// the resource name the user chose for each of the chapter's variables and
+// becomes input.expectations.
+//
+// Every declared variable must have a value and every value must belong to
+// a declared variable; otherwise the error joins a *MissingExpectationError
+// or *userguides.UnknownVariableError for each offending name. A variable's
+// resource type also requires the source of that resource to be configured,
+// e.g. a stack variable requires a StackSource.
+//
+// A value may name a resource that does not exist yet, since steps check
+// that the user creates it, but not one that only exists as a different
+// resource type: a stack variable filled with the name of a policy returns
+// a *ResourceTypeError.
+func (b *ValidationInputBuilder) Build(ctx context.Context, chapter userguides.Chapter, values map[string]string) (userguides.ValidationInput, error) {
+	expectations, err := b.expectations(chapter, values)
+	if err != nil {
+		return userguides.ValidationInput{}, err
+	}
+
+	input := userguides.ValidationInput{Expectations: expectations}
+	if b.stacks != nil {
+		if input.Stacks, err = list(ctx, b.stacks.ListStacks); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list stacks: %w", err)
+		}
+	}
+	if b.runs != nil {
+		if input.Runs, err = list(ctx, b.runs.ListRuns); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list runs: %w", err)
+		}
+	}
+	if b.aws != nil {
+		if input.AWSIntegrations, err = list(ctx, b.aws.ListAWSIntegrations); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list AWS integrations: %w", err)
+		}
+		if input.AWSAttachments, err = list(ctx, b.aws.ListAWSAttachments); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list AWS attachments: %w", err)
+		}
+	}
+	if b.policies != nil {
+		if input.Policies, err = list(ctx, b.policies.ListPolicies); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list policies: %w", err)
+		}
+		if input.PolicyAttachments, err = list(ctx, b.policies.ListPolicyAttachments); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list policy attachments: %w", err)
+		}
+	}
+	if b.contexts != nil {
+		if input.Contexts, err = list(ctx, b.contexts.ListContexts); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list contexts: %w", err)
+		}
+		if input.ContextAttachments, err = list(ctx, b.contexts.ListContextAttachments); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list context attachments: %w", err)
+		}
+	}
+	if b.spaces != nil {
+		if input.Spaces, err = list(ctx, b.spaces.ListSpaces); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list spaces: %w", err)
+		}
+	}
+	if b.dependencies != nil {
+		if input.StackDependencies, err = list(ctx, b.dependencies.ListStackDependencies); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list stack dependencies: %w", err)
+		}
+		if input.StackDependencyReferences, err = list(ctx, b.dependencies.ListStackDependencyReferences); err != nil {
+			return userguides.ValidationInput{}, fmt.Errorf("list stack dependency references: %w", err)
+		}
+	}
+
+	if err := checkResourceTypes(chapter, input); err != nil {
+		return userguides.ValidationInput{}, err
+	}
+	return input, nil
+}
+
+// list returns the items fn lists, as an empty rather than nil slice if
+// there are none: the key of a configured source is then [] in the input
+// document instead of missing, so that a validation such as
+// count(input.stacks) == 0 does not read an undefined value.
+func list[T any](ctx context.Context, fn func(context.Context) ([]T, error)) ([]T, error) {
+	items, err := fn(ctx)
+	if items == nil {
+		items = []T{}
+	}
+	return items, err
+}
+
+// MissingExpectationError reports a chapter variable for which no resource
+// was chosen.
+type MissingExpectationError struct {
+	Name    string
+	Chapter string
+}
+
+func (e *MissingExpectationError) Error() string {
+	return fmt.Sprintf("no resource chosen for variable %q of chapter %s", e.Name, e.Chapter)
+}
+
+// ResourceTypeError reports a variable whose value names a resource of
+// another type than the variable's, and none of its own type.
+type ResourceTypeError struct {
+	Name  string
+	Value string
+	// Want is the variable's resource type, Got the type of the resource
+	// the value names.
+	Want userguides.VariableResourceType
+	Got  userguides.VariableResourceType
+}
+
+func (e *ResourceTypeError) Error() string {
+	return fmt.Sprintf("variable %q has resource type %s, but %q is a %s", e.Name, e.Want, e.Value, e.Got)
+}
+
+// expectations maps the chapter's variables to the chosen resource names,
+// checking that the source of each variable's resource type is configured.
+func (b *ValidationInputBuilder) expectations(chapter userguides.Chapter, values map[string]string) (map[string]string, error) {
+	var errs []error
+
+	declared := make(map[string]bool, len(chapter.Variables))
+	expectations := make(map[string]string, len(chapter.Variables))
+	for _, v := range chapter.Variables {
+		declared[v.Name] = true
+
+		value, ok := values[v.Name]
+		if !ok {
+			errs = append(errs, &MissingExpectationError{Name: v.Name, Chapter: chapter.Slug})
+			continue
+		}
+		if !b.hasSource(v.ResourceType) {
+			errs = append(errs, fmt.Errorf("chapter %s: variable %q has resource type %s, whose source is not configured", chapter.Slug, v.Name, v.ResourceType))
+			continue
+		}
+		expectations[v.Name] = value
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !declared[name] {
+			errs = append(errs, &userguides.UnknownVariableError{Name: name, Chapter: chapter.Slug})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return expectations, nil
+}
+
+// hasSource reports whether the source of resources of type t is configured.
+func (b *ValidationInputBuilder) hasSource(t userguides.VariableResourceType) bool {
+	switch t {
+	case userguides.VariableResourceTypeStack:
+		return b.stacks != nil
+	case userguides.VariableResourceTypePolicy:
+		return b.policies != nil
+	case userguides.VariableResourceTypeAWSIntegration:
+		return b.aws != nil
+	case userguides.VariableResourceTypeContext:
+		return b.contexts != nil
+	case userguides.VariableResourceTypeSpace:
+		return b.spaces != nil
+	default:
+		return false
+	}
+}
+
+// checkResourceTypes checks that the value of each of the chapter's
+// variables does not name a resource of another type only.
+func checkResourceTypes(chapter userguides.Chapter, input userguides.ValidationInput) error {
+	names := resourceNames(input)
+
+	var errs []error
+	for _, v := range chapter.Variables {
+		value := input.Expectations[v.Name]
+		if names[v.ResourceType][value] {
+			continue
+		}
+		for _, t := range resourceTypes {
+			if t != v.ResourceType && names[t][value] {
+				errs = append(errs, &ResourceTypeError{Name: v.Name, Value: value, Want: v.ResourceType, Got: t})
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resourceTypes lists the variable resource types in the order
+// checkResourceTypes reports them.
+var resourceTypes = []userguides.VariableResourceType{
+	userguides.VariableResourceTypeStack,
+	userguides.VariableResourceTypePolicy,
+	userguides.VariableResourceTypeAWSIntegration,
+	userguides.VariableResourceTypeContext,
+	userguides.VariableResourceTypeSpace,
+}
+
+// resourceNames returns the names of the resources in input by type.
+func resourceNames(input userguides.ValidationInput) map[userguides.VariableResourceType]map[string]bool {
+	names := make(map[userguides.VariableResourceType]map[string]bool, len(resourceTypes))
+	add := func(t userguides.VariableResourceType, name string) {
+		if names[t] == nil {
+			names[t] = make(map[string]bool)
+		}
+		names[t][name] = true
+	}
+	for _, s := range input.Stacks {
+		add(userguides.VariableResourceTypeStack, s.Name)
+	}
+	for _, p := range input.Policies {
+		add(userguides.VariableResourceTypePolicy, p.Name)
+	}
+	for _, i := range input.AWSIntegrations {
+		add(userguides.VariableResourceTypeAWSIntegration, i.Name)
+	}
+	for _, c := range input.Contexts {
+		add(userguides.VariableResourceTypeContext, c.Name)
+	}
+	for _, s := range input.Spaces {
+		add(userguides.VariableResourceTypeSpace, s.Name)
+	}
+	return names
+}
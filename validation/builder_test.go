@@ -0,0 +1,166 @@
+package validation_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/validation"
+)
+
+func gettingStartedValues() map[string]string {
+	return map[string]string{
+		"main_stack_name":       "Ground control",
+		"aws_integration_name":  "Ground control AWS",
+		"policy_name":           "Ground control plan policy",
+		"dependency_stack_name": "Networking",
+	}
+}
+
+func gettingStarted(t *testing.T) (*userguides.Library, *userguides.Chapter) {
+	t.Helper()
+
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+	chapter, ok := lib.ChapterByPath("foundations", "getting-started")
+	if !ok {
+		t.Fatal("Expected the foundations/getting-started chapter to exist")
+	}
+	return lib, chapter
+}
+
+func TestValidationInputBuilder(t *testing.T) {
+	lib, chapter := gettingStarted(t)
+
+	snapshot := &validation.Snapshot{
+		Stacks: []userguides.InputStack{{
+			ID:                   "ground-control",
+			Name:                 "Ground control",
+			SpaceSlug:            "root",
+			Labels:               []string{},
+			EnvironmentVariables: []string{},
+			Dependencies:         []string{},
+		}},
+		Runs: []userguides.InputRun{{ID: "run-1", StackID: "ground-control", Type: "TRACKED", Status: "FINISHED"}},
+	}
+
+	input, err := validation.NewValidationInputBuilder(validation.WithAccountSource(snapshot)).
+		Build(context.Background(), *chapter, gettingStartedValues())
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal input: %v", err)
+	}
+	want := `{"expectations":{"aws_integration_name":"Ground control AWS","dependency_stack_name":"Networking","main_stack_name":"Ground control","policy_name":"Ground control plan policy"},` +
+		`"stacks":[{"id":"ground-control","name":"Ground control","space_slug":"root","autodeploy":false,"project_root":"","labels":[],"environment_variables":[],"has_before_init_hooks":false,"dependencies":[]}],` +
+		`"runs":[{"id":"run-1","created_at":0,"stack_id":"ground-control","type":"TRACKED","status":"FINISHED"}],` +
+		`"aws_integrations":[],"aws_attachments":[],"policies":[],"policy_attachments":[],"contexts":[],"context_attachments":[],` +
+		`"spaces":[],"stack_dependencies":[],"stack_dependency_references":[]}`
+	if string(data) != want {
+		t.Errorf("input =\n%s\nwant\n%s", data, want)
+	}
+
+	guide, _ := lib.GuideBySlug("ground-control-first-stack")
+	result, err := validation.EvaluateStep(context.Background(), *guide, 4, input)
+	if err != nil {
+		t.Fatalf("EvaluateStep returned error: %v", err)
+	}
+	if !result.Valid {
+		t.Error("Expected the built input to pass step 4")
+	}
+}
+
+func TestValidationInputBuilder_EmptySources(t *testing.T) {
+	_, chapter := gettingStarted(t)
+
+	snapshot := &validation.Snapshot{}
+	input, err := validation.NewValidationInputBuilder(
+		validation.WithStackSource(snapshot),
+		validation.WithAWSIntegrationSource(snapshot),
+		validation.WithPolicySource(snapshot),
+	).Build(context.Background(), *chapter, gettingStartedValues())
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal input: %v", err)
+	}
+	// Configured sources that list nothing are sent as [], so that
+	// count(input.stacks) == 0 holds; the others are left out.
+	for _, key := range []string{"stacks", "aws_integrations", "aws_attachments", "policies", "policy_attachments"} {
+		if !strings.Contains(string(data), `"`+key+`":[]`) {
+			t.Errorf("expected %q to be [], got %s", key, data)
+		}
+	}
+	if strings.Contains(string(data), `"runs"`) {
+		t.Errorf("expected runs, which have no source, to be left out, got %s", data)
+	}
+}
+
+func TestValidationInputBuilder_Errors(t *testing.T) {
+	_, chapter := gettingStarted(t)
+
+	values := gettingStartedValues()
+	delete(values, "policy_name")
+	values["stack_name"] = "typo"
+
+	_, err := validation.NewValidationInputBuilder(validation.WithAccountSource(&validation.Snapshot{})).
+		Build(context.Background(), *chapter, values)
+
+	var missing *validation.MissingExpectationError
+	if !errors.As(err, &missing) || missing.Name != "policy_name" {
+		t.Errorf("Expected a MissingExpectationError for policy_name, got: %v", err)
+	}
+	var unknown *userguides.UnknownVariableError
+	if !errors.As(err, &unknown) || unknown.Name != "stack_name" {
+		t.Errorf("Expected an UnknownVariableError for stack_name, got: %v", err)
+	}
+}
+
+func TestValidationInputBuilder_ResourceType(t *testing.T) {
+	_, chapter := gettingStarted(t)
+
+	snapshot := &validation.Snapshot{
+		Policies: []userguides.InputPolicy{{Name: "Ground control plan policy", Type: "PLAN", SpaceSlug: "root"}},
+	}
+	builder := validation.NewValidationInputBuilder(validation.WithAccountSource(snapshot))
+
+	values := gettingStartedValues()
+	values["main_stack_name"] = "Ground control plan policy"
+	_, err := builder.Build(context.Background(), *chapter, values)
+
+	var mismatch *validation.ResourceTypeError
+	if !errors.As(err, &mismatch) || mismatch.Name != "main_stack_name" || mismatch.Got != userguides.VariableResourceTypePolicy {
+		t.Errorf("Expected a ResourceTypeError for main_stack_name, got: %v", err)
+	}
+
+	// A name shared by resources of both types is accepted.
+	snapshot.Stacks = []userguides.InputStack{{ID: "plan-policy", Name: "Ground control plan policy"}}
+	if _, err := builder.Build(context.Background(), *chapter, values); err != nil {
+		t.Errorf("Build returned error: %v", err)
+	}
+}
+
+func TestValidationInputBuilder_MissingSource(t *testing.T) {
+	_, chapter := gettingStarted(t)
+
+	snapshot := &validation.Snapshot{}
+	_, err := validation.NewValidationInputBuilder(
+		validation.WithStackSource(snapshot),
+		validation.WithPolicySource(snapshot),
+	).Build(context.Background(), *chapter, gettingStartedValues())
+
+	if err == nil || !strings.Contains(err.Error(), `"aws_integration_name" has resource type aws_integration, whose source is not configured`) {
+		t.Errorf("Expected the missing AWS integration source to be reported, got: %v", err)
+	}
+}
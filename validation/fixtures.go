@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// Fixture is a recorded validation input for one guide step, along with the
+// outcome the step's validation must produce for it.
+//
+// Fixtures are laid out as <guide-slug>/<step-order>/pass_<name>.json for
+// inputs the validation must accept and fail_<name>.json for inputs it must
+// reject. A guide may also have a <guide-slug>/history.yaml, see History.
+type Fixture struct {
+	// Path is the fixture's path within the fixtures file system.
+	Path  string
+	Guide string
+	Step  int
+	// Pass reports whether the validation must accept the input.
+	Pass  bool
+	Input any
+}
+
+// LoadFixtures reads every fixture in fsys. Files that do not follow the
+// fixture layout are reported as errors rather than skipped, so a misnamed
+// fixture cannot silently stop being checked.
+func LoadFixtures(fsys fs.FS) ([]Fixture, error) {
+	var (
+		fixtures []Fixture
+		errs     []error
+	)
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || isHistory(p) {
+			return nil
+		}
+
+		fixture, err := parseFixturePath(p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		// Numbers are kept as json.Number: run timestamps are nanoseconds and
+		// do not fit in a float64.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&fixture.Input); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p, err))
+			return nil
+		}
+
+		fixtures = append(fixtures, fixture)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fixtures, errors.Join(errs...)
+}
+
+// HistoryFile is the name of the file holding a guide's scripted account
+// history, next to its step fixtures: <guide-slug>/history.yaml.
+const HistoryFile = "history.yaml"
+
+func isHistory(p string) bool {
+	dir, file := path.Split(p)
+	return file == HistoryFile && !strings.Contains(strings.TrimSuffix(dir, "/"), "/")
+}
+
+// parseFixturePath extracts the guide, step and expected outcome from a
+// fixture path.
+func parseFixturePath(p string) (Fixture, error) {
+	parts := strings.Split(p, "/")
+	if len(parts) != 3 {
+		return Fixture{}, errors.New("fixtures must be laid out as <guide>/<step>/<pass|fail>_<name>.json")
+	}
+
+	step, err := strconv.Atoi(parts[1])
+	if err != nil || step <= 0 {
+		return Fixture{}, fmt.Errorf("step directory %q must be a positive step order", parts[1])
+	}
+
+	name := parts[2]
+	if path.Ext(name) != ".json" {
+		return Fixture{}, errors.New("fixtures must be .json files")
+	}
+
+	fixture := Fixture{Path: p, Guide: parts[0], Step: step}
+	switch {
+	case strings.HasPrefix(name, "pass_"):
+		fixture.Pass = true
+	case strings.HasPrefix(name, "fail_"):
+		fixture.Pass = false
+	default:
+		return Fixture{}, errors.New("fixture names must start with pass_ or fail_")
+	}
+
+	return fixture, nil
+}
+
+// FixtureResult is the outcome of running a single fixture.
+type FixtureResult struct {
+	Fixture Fixture
+	Result  Result
+	// Err is set when the fixture could not be evaluated, e.g. because its
+	// guide or step does not exist or the step has no validation.
+	Err error
+}
+
+// OK reports whether the fixture was evaluated and produced the expected
+// outcome.
+func (r FixtureResult) OK() bool {
+	return r.Err == nil && r.Result.Valid == r.Fixture.Pass
+}
+
+func (r FixtureResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: %v", r.Fixture.Path, r.Err)
+	case r.OK():
+		return fmt.Sprintf("%s: ok", r.Fixture.Path)
+	case r.Fixture.Pass:
+		msg := fmt.Sprintf("%s: expected the validation to pass, but it failed", r.Fixture.Path)
+		if len(r.Result.Deny) > 0 {
+			msg += ": " + strings.Join(r.Result.Deny, "; ")
+		}
+		return msg
+	default:
+		return fmt.Sprintf("%s: expected the validation to fail, but it passed", r.Fixture.Path)
+	}
+}
+
+// RunFixtures evaluates each fixture against the validation of its step in
+// lib, returning one result per fixture in the same order.
+func (e *Evaluator) RunFixtures(ctx context.Context, lib *userguides.Library, fixtures []Fixture) []FixtureResult {
+	results := make([]FixtureResult, len(fixtures))
+	for i, fixture := range fixtures {
+		results[i].Fixture = fixture
+
+		guide, ok := lib.GuideBySlug(fixture.Guide)
+		if !ok {
+			results[i].Err = fmt.Errorf("guide %s not found", fixture.Guide)
+			continue
+		}
+
+		results[i].Result, results[i].Err = e.EvaluateStep(ctx, *guide, fixture.Step, fixture.Input)
+	}
+	return results
+}
+
+// RunFixtures runs fixtures using a package-level Evaluator. See
+// Evaluator.RunFixtures.
+func RunFixtures(ctx context.Context, lib *userguides.Library, fixtures []Fixture) []FixtureResult {
+	return defaultEvaluator.RunFixtures(ctx, lib, fixtures)
+}
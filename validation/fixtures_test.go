@@ -0,0 +1,122 @@
+package validation_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/validation"
+)
+
+// TestFixtures runs every fixture under fixtures/ against the embedded
+// library.
+func TestFixtures(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	fixtures, err := validation.LoadFixtures(os.DirFS("../fixtures"))
+	if err != nil {
+		t.Fatalf("LoadFixtures returned error: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("Expected at least one fixture")
+	}
+
+	schema := inputSchema(t)
+
+	for _, result := range validation.RunFixtures(context.Background(), lib, fixtures) {
+		t.Run(result.Fixture.Path, func(t *testing.T) {
+			if err := schema.Validate(result.Fixture.Input); err != nil {
+				t.Errorf("Fixture does not match the validation input schema:\n%v", err)
+			}
+			if !result.OK() {
+				t.Error(result)
+			}
+		})
+	}
+}
+
+func inputSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	data, err := userguides.ValidationInputSchema()
+	if err != nil {
+		t.Fatalf("ValidationInputSchema returned error: %v", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse the validation input schema: %v", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("validation_input_schema.json", doc); err != nil {
+		t.Fatalf("Failed to add schema resource: %v", err)
+	}
+	schema, err := c.Compile("validation_input_schema.json")
+	if err != nil {
+		t.Fatalf("Failed to compile the validation input schema: %v", err)
+	}
+	return schema
+}
+
+func TestLoadFixtures_Layout(t *testing.T) {
+	f := fstest.MapFS{
+		"guide-one/1/pass_ok.json":  {Data: []byte(`{"stacks": []}`)},
+		"guide-one/1/fail_ok.json":  {Data: []byte(`{}`)},
+		"guide-one/1/passing.json":  {Data: []byte(`{}`)},
+		"guide-one/first/pass.json": {Data: []byte(`{}`)},
+		"guide-one/pass_flat.json":  {Data: []byte(`{}`)},
+		"guide-one/2/pass_bad.json": {Data: []byte(`{`)},
+		"guide-one/history.yaml":    {Data: []byte(`points: []`)},
+	}
+
+	fixtures, err := validation.LoadFixtures(f)
+	if len(fixtures) != 2 {
+		t.Errorf("Expected the 2 well-formed fixtures to load, got %d", len(fixtures))
+	}
+	for _, fixture := range fixtures {
+		if fixture.Guide != "guide-one" || fixture.Step != 1 || fixture.Pass != strings.Contains(fixture.Path, "pass_") {
+			t.Errorf("Unexpected fixture: %+v", fixture)
+		}
+	}
+
+	if err == nil {
+		t.Fatal("Expected malformed fixtures to be reported")
+	}
+	for _, path := range []string{"guide-one/1/passing.json", "guide-one/first/pass.json", "guide-one/pass_flat.json", "guide-one/2/pass_bad.json"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("Expected %s to be reported, got: %v", path, err)
+		}
+	}
+}
+
+func TestRunFixtures_Failures(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	fixtures := []validation.Fixture{
+		{Path: "missing", Guide: "no-such-guide", Step: 1, Pass: true},
+		{Path: "wrong", Guide: "ground-control-first-stack", Step: 3, Pass: true, Input: map[string]any{"stacks": []any{}}},
+	}
+
+	results := validation.RunFixtures(context.Background(), lib, fixtures)
+	if results[0].OK() || results[0].Err == nil {
+		t.Errorf("Expected an unknown guide to be an error, got: %v", results[0])
+	}
+	if results[1].OK() || results[1].Err != nil {
+		t.Errorf("Expected a failed expectation without error, got: %v", results[1])
+	}
+	if !strings.Contains(results[1].String(), "expected the validation to pass") {
+		t.Errorf("Unexpected message: %s", results[1])
+	}
+}
@@ -0,0 +1,202 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// History is a scripted account history for a guide: the state of the
+// account after each user action, in order.
+type History struct {
+	// Expectations holds the resource name the user chose for each chapter
+	// variable, as in ValidationInput.
+	Expectations map[string]string `json:"expectations"`
+	Points       []HistoryPoint    `json:"points"`
+}
+
+// HistoryPoint is the state of the account once the user has finished the
+// step with order After. After is zero for the state before the first step.
+type HistoryPoint struct {
+	After       int    `json:"after"`
+	Description string `json:"description,omitempty"`
+	Snapshot
+}
+
+// ParseHistory decodes a History from YAML (or JSON), e.g. a
+// fixtures/<guide>/history.yaml file. Unknown keys are errors.
+func ParseHistory(data []byte) (History, error) {
+	// Decode into generic values first and re-encode as JSON, so the JSON
+	// field names of the input model apply.
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return History{}, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return History{}, err
+	}
+
+	// Unknown keys are errors, as in guide files, so that a misspelled key
+	// does not silently leave the history empty.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var h History
+	if err := dec.Decode(&h); err != nil {
+		return History{}, err
+	}
+
+	for i := 1; i < len(h.Points); i++ {
+		if h.Points[i].After < h.Points[i-1].After {
+			return History{}, fmt.Errorf("points[%d]: after %d comes before the previous point's after %d", i, h.Points[i].After, h.Points[i-1].After)
+		}
+	}
+
+	return h, nil
+}
+
+// SimulationPoint is the outcome of evaluating every step validation at one
+// point of a History.
+type SimulationPoint struct {
+	After       int
+	Description string
+	// Passing lists the orders of the steps whose validation passes.
+	Passing []int
+}
+
+// SimulationProblemKind classifies a SimulationProblem.
+type SimulationProblemKind string
+
+const (
+	// SimulationPremature means a step's validation already passes when the
+	// user reaches the step, so it would be marked complete before the user
+	// does anything.
+	SimulationPremature SimulationProblemKind = "premature"
+	// SimulationNeverPasses means a step's validation does not pass at any
+	// point after the user has done the step.
+	SimulationNeverPasses SimulationProblemKind = "never-passes"
+)
+
+// SimulationProblem is a step whose validation does not follow the history.
+type SimulationProblem struct {
+	Step int
+	Kind SimulationProblemKind
+}
+
+func (p SimulationProblem) String() string {
+	switch p.Kind {
+	case SimulationPremature:
+		return fmt.Sprintf("step %d: validation passes before the user does the step", p.Step)
+	case SimulationNeverPasses:
+		return fmt.Sprintf("step %d: validation never passes after the user does the step", p.Step)
+	default:
+		return fmt.Sprintf("step %d: %s", p.Step, p.Kind)
+	}
+}
+
+// Simulation is the result of replaying a History through a guide.
+type Simulation struct {
+	Guide    string
+	Points   []SimulationPoint
+	Problems []SimulationProblem
+}
+
+// Simulate evaluates every step validation of guide at each point of
+// history.
+//
+// Steps are checked in order, so a step only matters once the user has
+// reached it: a step is premature if its validation passes at the last point
+// before the user does it, and never passes if it fails at every point from
+// the one where the user has done it onwards. A step the history does not
+// reach never passes either, so a history must cover every step with a
+// validation.
+func (e *Evaluator) Simulate(ctx context.Context, guide userguides.Guide, history History) (*Simulation, error) {
+	sim := &Simulation{Guide: guide.Slug}
+
+	for _, point := range history.Points {
+		input := point.input(history.Expectations)
+
+		simPoint := SimulationPoint{After: point.After, Description: point.Description}
+		for _, step := range guide.Steps {
+			if step.Validation == "" {
+				continue
+			}
+			result, err := e.EvaluateStep(ctx, guide, step.Order, input)
+			if err != nil {
+				return nil, err
+			}
+			if result.Valid {
+				simPoint.Passing = append(simPoint.Passing, step.Order)
+			}
+		}
+		sim.Points = append(sim.Points, simPoint)
+	}
+
+	for _, step := range guide.Steps {
+		if step.Validation == "" {
+			continue
+		}
+
+		before := -1
+		passed := false
+		for i, point := range sim.Points {
+			if point.After < step.Order {
+				before = i
+				continue
+			}
+			if slices.Contains(point.Passing, step.Order) {
+				passed = true
+				break
+			}
+		}
+
+		if before >= 0 && slices.Contains(sim.Points[before].Passing, step.Order) {
+			sim.Problems = append(sim.Problems, SimulationProblem{Step: step.Order, Kind: SimulationPremature})
+		}
+		if !passed {
+			sim.Problems = append(sim.Problems, SimulationProblem{Step: step.Order, Kind: SimulationNeverPasses})
+		}
+	}
+
+	return sim, nil
+}
+
+// Simulate replays history through guide using a package-level Evaluator.
+// See Evaluator.Simulate.
+func Simulate(ctx context.Context, guide userguides.Guide, history History) (*Simulation, error) {
+	return defaultEvaluator.Simulate(ctx, guide, history)
+}
+
+// input returns the validation input for the point. A point is the whole
+// account, so a collection it leaves out is sent as [], as the builder does
+// for a configured source that lists nothing.
+func (p HistoryPoint) input(expectations map[string]string) userguides.ValidationInput {
+	return userguides.ValidationInput{
+		Expectations:              expectations,
+		Stacks:                    nonNil(p.Stacks),
+		Runs:                      nonNil(p.Runs),
+		AWSIntegrations:           nonNil(p.AWSIntegrations),
+		AWSAttachments:            nonNil(p.AWSAttachments),
+		Policies:                  nonNil(p.Policies),
+		PolicyAttachments:         nonNil(p.PolicyAttachments),
+		Contexts:                  nonNil(p.Contexts),
+		ContextAttachments:        nonNil(p.ContextAttachments),
+		Spaces:                    nonNil(p.Spaces),
+		StackDependencies:         nonNil(p.StackDependencies),
+		StackDependencyReferences: nonNil(p.StackDependencyReferences),
+	}
+}
+
+// nonNil returns s, or an empty slice if s is nil.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,170 @@
+package validation_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/validation"
+)
+
+// TestHistories replays every fixtures/<guide>/history.yaml through its
+// guide.
+func TestHistories(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	fsys := os.DirFS("../fixtures")
+	paths, err := fs.Glob(fsys, "*/"+validation.HistoryFile)
+	if err != nil {
+		t.Fatalf("Failed to list histories: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("Expected at least one history")
+	}
+
+	for _, p := range paths {
+		t.Run(p, func(t *testing.T) {
+			data, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				t.Fatalf("Failed to read history: %v", err)
+			}
+			history, err := validation.ParseHistory(data)
+			if err != nil {
+				t.Fatalf("ParseHistory returned error: %v", err)
+			}
+
+			slug := path.Dir(p)
+			guide, ok := lib.GuideBySlug(slug)
+			if !ok {
+				t.Fatalf("Guide %s not found", slug)
+			}
+
+			sim, err := validation.Simulate(context.Background(), *guide, history)
+			if err != nil {
+				t.Fatalf("Simulate returned error: %v", err)
+			}
+			for _, problem := range sim.Problems {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+func TestSimulate_Problems(t *testing.T) {
+	stackExists := `package spacelift
+
+valid if {
+	some stack in input.stacks
+	stack.name == input.expectations.main_stack_name
+}`
+	never := `package spacelift
+
+valid if {
+	some run in input.runs
+	run.type == "DESTROY"
+}`
+
+	guide := userguides.Guide{
+		Slug: "simulated",
+		Steps: []userguides.GuideStep{
+			{Order: 1, Validation: stackExists},
+			{Order: 2},
+			{Order: 3, Validation: stackExists},
+			{Order: 4, Validation: never},
+			{Order: 5, Validation: never},
+		},
+	}
+
+	history, err := validation.ParseHistory([]byte(`
+expectations:
+  main_stack_name: "Stack"
+points:
+  - after: 0
+  - after: 1
+    stacks:
+      - {id: "stack", name: "Stack", space_slug: "root", autodeploy: false, project_root: "", labels: [], environment_variables: [], has_before_init_hooks: false, dependencies: []}
+  - after: 4
+    stacks:
+      - {id: "stack", name: "Stack", space_slug: "root", autodeploy: false, project_root: "", labels: [], environment_variables: [], has_before_init_hooks: false, dependencies: []}
+`))
+	if err != nil {
+		t.Fatalf("ParseHistory returned error: %v", err)
+	}
+
+	sim, err := validation.Simulate(context.Background(), guide, history)
+	if err != nil {
+		t.Fatalf("Simulate returned error: %v", err)
+	}
+
+	// Step 3 passes as soon as step 1 is done, step 4 never passes and step
+	// 5, which is beyond the end of the history, cannot be shown to pass.
+	want := []validation.SimulationProblem{
+		{Step: 3, Kind: validation.SimulationPremature},
+		{Step: 4, Kind: validation.SimulationNeverPasses},
+		{Step: 5, Kind: validation.SimulationNeverPasses},
+	}
+	if len(sim.Problems) != len(want) {
+		t.Fatalf("Problems = %v, want %v", sim.Problems, want)
+	}
+	for i := range want {
+		if sim.Problems[i] != want[i] {
+			t.Errorf("Problems[%d] = %v, want %v", i, sim.Problems[i], want[i])
+		}
+	}
+
+	if passing := sim.Points[1].Passing; len(passing) != 2 || passing[0] != 1 || passing[1] != 3 {
+		t.Errorf("Expected steps 1 and 3 to pass after step 1, got %v", passing)
+	}
+}
+
+func TestSimulate_EmptyCollections(t *testing.T) {
+	guide := userguides.Guide{
+		Slug: "simulated",
+		Steps: []userguides.GuideStep{
+			{Order: 1, Validation: "package spacelift\n\nvalid if count(input.runs) == 0\n"},
+		},
+	}
+
+	// The point lists no runs, which is different from not knowing them.
+	history, err := validation.ParseHistory([]byte("points:\n  - after: 0\n    stacks: []\n  - after: 1\n"))
+	if err != nil {
+		t.Fatalf("ParseHistory returned error: %v", err)
+	}
+
+	sim, err := validation.Simulate(context.Background(), guide, history)
+	if err != nil {
+		t.Fatalf("Simulate returned error: %v", err)
+	}
+	if passing := sim.Points[1].Passing; len(passing) != 1 {
+		t.Errorf("Expected count(input.runs) == 0 to hold without runs, got %v", sim.Points[1])
+	}
+}
+
+func TestParseHistory_OutOfOrder(t *testing.T) {
+	_, err := validation.ParseHistory([]byte("points:\n  - after: 2\n  - after: 1\n"))
+	if err == nil {
+		t.Error("Expected points out of order to be rejected")
+	}
+}
+
+func TestParseHistory_UnknownKeys(t *testing.T) {
+	tests := map[string]string{
+		"top level":  "expectations: {}\npoints:\n  - after: 0\npasses: []\n",
+		"point":      "points:\n  - after: 0\n    step: 1\n",
+		"in a stack": "points:\n  - after: 0\n    stacks:\n      - id: a\n        nmae: A\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := validation.ParseHistory([]byte(data)); err == nil || !strings.Contains(err.Error(), "unknown field") {
+				t.Errorf("Expected an unknown field error, got %v", err)
+			}
+		})
+	}
+}
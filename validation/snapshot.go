@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"context"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+)
+
+// Snapshot is an in-memory AccountSource: a fixed view of the resources in
+// an account. It decodes from the same JSON as ValidationInput, without the
+// expectations.
+type Snapshot struct {
+	Stacks                    []userguides.InputStack                    `json:"stacks,omitempty"`
+	Runs                      []userguides.InputRun                      `json:"runs,omitempty"`
+	AWSIntegrations           []userguides.InputAWSIntegration           `json:"aws_integrations,omitempty"`
+	AWSAttachments            []userguides.InputAWSAttachment            `json:"aws_attachments,omitempty"`
+	Policies                  []userguides.InputPolicy                   `json:"policies,omitempty"`
+	PolicyAttachments         []userguides.InputPolicyAttachment         `json:"policy_attachments,omitempty"`
+	Contexts                  []userguides.InputContext                  `json:"contexts,omitempty"`
+	ContextAttachments        []userguides.InputContextAttachment        `json:"context_attachments,omitempty"`
+	Spaces                    []userguides.InputSpace                    `json:"spaces,omitempty"`
+	StackDependencies         []userguides.InputStackDependency          `json:"stack_dependencies,omitempty"`
+	StackDependencyReferences []userguides.InputStackDependencyReference `json:"stack_dependency_references,omitempty"`
+}
+
+var _ AccountSource = (*Snapshot)(nil)
+
+func (s *Snapshot) ListStacks(context.Context) ([]userguides.InputStack, error) {
+	return s.Stacks, nil
+}
+
+func (s *Snapshot) ListRuns(context.Context) ([]userguides.InputRun, error) {
+	return s.Runs, nil
+}
+
+func (s *Snapshot) ListAWSIntegrations(context.Context) ([]userguides.InputAWSIntegration, error) {
+	return s.AWSIntegrations, nil
+}
+
+func (s *Snapshot) ListAWSAttachments(context.Context) ([]userguides.InputAWSAttachment, error) {
+	return s.AWSAttachments, nil
+}
+
+func (s *Snapshot) ListPolicies(context.Context) ([]userguides.InputPolicy, error) {
+	return s.Policies, nil
+}
+
+func (s *Snapshot) ListPolicyAttachments(context.Context) ([]userguides.InputPolicyAttachment, error) {
+	return s.PolicyAttachments, nil
+}
+
+func (s *Snapshot) ListContexts(context.Context) ([]userguides.InputContext, error) {
+	return s.Contexts, nil
+}
+
+func (s *Snapshot) ListContextAttachments(context.Context) ([]userguides.InputContextAttachment, error) {
+	return s.ContextAttachments, nil
+}
+
+func (s *Snapshot) ListSpaces(context.Context) ([]userguides.InputSpace, error) {
+	return s.Spaces, nil
+}
+
+func (s *Snapshot) ListStackDependencies(context.Context) ([]userguides.InputStackDependency, error) {
+	return s.StackDependencies, nil
+}
+
+func (s *Snapshot) ListStackDependencyReferences(context.Context) ([]userguides.InputStackDependencyReference, error) {
+	return s.StackDependencyReferences, nil
+}
@@ -0,0 +1,149 @@
+// Package validation evaluates the Rego policies that decide whether a guide
+// step has been completed.
+//
+// Each GuideStep.Validation declares package spacelift and defines a valid
+// rule, which must evaluate to true once the user has done what the step
+// asks. A policy may also define a deny rule, a set of strings explaining
+// what is still missing.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/internal/policy"
+)
+
+// ErrNoValidation is returned for steps without a validation policy. Such
+// steps are completed by the user rather than by evaluation.
+var ErrNoValidation = errors.New("step has no validation")
+
+// Result is the outcome of evaluating a step validation.
+type Result struct {
+	// Valid reports whether the policy's valid rule evaluated to true.
+	Valid bool
+	// Deny holds the messages produced by the policy's deny rule, sorted.
+	// It is empty when the policy defines no deny rule.
+	Deny []string
+}
+
+// Evaluator evaluates step validations, compiling each distinct policy once.
+// It is safe for concurrent use.
+type Evaluator struct {
+	mu       sync.Mutex
+	prepared map[string]*rego.PreparedEvalQuery
+}
+
+// NewEvaluator returns an Evaluator with an empty cache.
+func NewEvaluator() *Evaluator {
+	return &Evaluator{
+		prepared: make(map[string]*rego.PreparedEvalQuery),
+	}
+}
+
+var defaultEvaluator = NewEvaluator()
+
+// EvaluateStep evaluates a step validation using a package-level Evaluator.
+// See Evaluator.EvaluateStep.
+func EvaluateStep(ctx context.Context, guide userguides.Guide, stepOrder int, input any) (Result, error) {
+	return defaultEvaluator.EvaluateStep(ctx, guide, stepOrder, input)
+}
+
+// EvaluateStep evaluates the validation of the step with the given order
+// against input, which must marshal to the JSON document the policy reads
+// as input. It returns ErrNoValidation if the step has no validation.
+func (e *Evaluator) EvaluateStep(ctx context.Context, guide userguides.Guide, stepOrder int, input any) (Result, error) {
+	var step *userguides.GuideStep
+	for i := range guide.Steps {
+		if guide.Steps[i].Order == stepOrder {
+			step = &guide.Steps[i]
+			break
+		}
+	}
+	if step == nil {
+		return Result{}, fmt.Errorf("guide %s has no step %d", guide.Slug, stepOrder)
+	}
+	if step.Validation == "" {
+		return Result{}, ErrNoValidation
+	}
+
+	query, err := e.prepare(ctx, fmt.Sprintf("%s/step-%d.rego", guide.Slug, step.Order), step.Validation)
+	if err != nil {
+		return Result{}, fmt.Errorf("guide %s: step %d: %w", guide.Slug, step.Order, err)
+	}
+
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return Result{}, fmt.Errorf("guide %s: step %d: evaluate validation: %w", guide.Slug, step.Order, err)
+	}
+
+	return resultFrom(rs), nil
+}
+
+// prepare returns the prepared query for a policy, compiling it on first use.
+func (e *Evaluator) prepare(ctx context.Context, name, source string) (*rego.PreparedEvalQuery, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if query, ok := e.prepared[source]; ok {
+		return query, nil
+	}
+
+	compiler, errs := policy.Compile(name, source, nil)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("compile validation: %w", errors.Join(toErrors(errs)...))
+	}
+
+	query, err := rego.New(
+		rego.Query("data."+policy.Package),
+		rego.Compiler(compiler),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("prepare validation: %w", err)
+	}
+
+	e.prepared[source] = &query
+	return &query, nil
+}
+
+func toErrors(errs []policy.Error) []error {
+	out := make([]error, len(errs))
+	for i, err := range errs {
+		out[i] = err
+	}
+	return out
+}
+
+// resultFrom reads the valid and deny rules from the evaluated package.
+func resultFrom(rs rego.ResultSet) Result {
+	var result Result
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return result
+	}
+
+	pkg, ok := rs[0].Expressions[0].Value.(map[string]any)
+	if !ok {
+		return result
+	}
+
+	result.Valid = pkg[policy.Rule] == true
+
+	if deny, ok := pkg[policy.DenyRule].([]any); ok {
+		for _, msg := range deny {
+			if s, ok := msg.(string); ok {
+				result.Deny = append(result.Deny, s)
+			} else {
+				result.Deny = append(result.Deny, fmt.Sprint(msg))
+			}
+		}
+		sort.Strings(result.Deny)
+	}
+
+	return result
+}
@@ -0,0 +1,122 @@
+package validation_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	userguides "github.com/spacelift-io/spacelift-user-guides-library"
+	"github.com/spacelift-io/spacelift-user-guides-library/validation"
+)
+
+func sampleInput(t *testing.T) map[string]any {
+	t.Helper()
+
+	data, err := os.ReadFile("../rego_input/sample_rego_input.json")
+	if err != nil {
+		t.Fatalf("Failed to read sample input: %v", err)
+	}
+
+	var input map[string]any
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatalf("Failed to parse sample input: %v", err)
+	}
+	return input
+}
+
+func TestEvaluateStep(t *testing.T) {
+	lib, err := userguides.Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	guide, ok := lib.GuideBySlug("ground-control-first-stack")
+	if !ok {
+		t.Fatal("Expected 'ground-control-first-stack' guide to exist")
+	}
+
+	input := sampleInput(t)
+
+	result, err := validation.EvaluateStep(context.Background(), *guide, 3, input)
+	if err != nil {
+		t.Fatalf("EvaluateStep returned error: %v", err)
+	}
+	if !result.Valid {
+		t.Error("Expected step 3 to be valid for the sample input")
+	}
+
+	input["expectations"].(map[string]any)["main_stack_name"] = "Missing stack"
+	result, err = validation.EvaluateStep(context.Background(), *guide, 3, input)
+	if err != nil {
+		t.Fatalf("EvaluateStep returned error: %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected step 3 to be invalid when the stack does not exist")
+	}
+
+	if _, err := validation.EvaluateStep(context.Background(), *guide, 1, input); !errors.Is(err, validation.ErrNoValidation) {
+		t.Errorf("Expected ErrNoValidation for step 1, got: %v", err)
+	}
+	if _, err := validation.EvaluateStep(context.Background(), *guide, 99, input); err == nil {
+		t.Error("Expected error for a step that does not exist")
+	}
+}
+
+func TestEvaluateStep_Deny(t *testing.T) {
+	guide := userguides.Guide{
+		Slug: "deny-guide",
+		Steps: []userguides.GuideStep{{
+			Order: 1,
+			Validation: `package spacelift
+
+deny contains "create a stack first" if {
+	count(input.stacks) == 0
+}
+
+deny contains "trigger a run" if {
+	count(input.runs) == 0
+}
+
+valid if {
+	count(deny) == 0
+}`,
+		}},
+	}
+
+	evaluator := validation.NewEvaluator()
+	result, err := evaluator.EvaluateStep(context.Background(), guide, 1, map[string]any{
+		"stacks": []any{},
+		"runs":   []any{},
+	})
+	if err != nil {
+		t.Fatalf("EvaluateStep returned error: %v", err)
+	}
+
+	if result.Valid {
+		t.Error("Expected the step to be invalid")
+	}
+	if got := strings.Join(result.Deny, ","); got != "create a stack first,trigger a run" {
+		t.Errorf("Deny = %q", got)
+	}
+}
+
+func TestEvaluateStep_BrokenPolicy(t *testing.T) {
+	guide := userguides.Guide{
+		Slug: "broken-guide",
+		Steps: []userguides.GuideStep{{
+			Order:      1,
+			Validation: "package other\n\nallow if { true }\n",
+		}},
+	}
+
+	_, err := validation.NewEvaluator().EvaluateStep(context.Background(), guide, 1, map[string]any{})
+	if err == nil {
+		t.Fatal("Expected error for a broken policy")
+	}
+	if !strings.Contains(err.Error(), "package spacelift") || !strings.Contains(err.Error(), `"valid" rule`) {
+		t.Errorf("Expected both the package and the missing rule to be reported, got: %v", err)
+	}
+}
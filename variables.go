@@ -0,0 +1,173 @@
+package userguides
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+)
+
+// expectationRefs returns every read of a named expectation in a validation
+// policy: input.expectations.name, input["expectations"]["name"], the same
+// through a variable or rule bound to input.expectations, and
+// object.get(input.expectations, "name", default). Comments and strings
+// are not references. Reads whose name is computed are left out, and a
+// policy that does not parse has no references.
+func expectationRefs(validation string) []placeholder {
+	module, err := ast.ParseModuleWithOpts("", validation, ast.ParserOptions{RegoVersion: ast.RegoV1})
+	if err != nil {
+		return nil
+	}
+
+	aliases := expectationAliases(module)
+	isExpectations := func(t *ast.Term) bool {
+		return isExpectationsDoc(t, aliases)
+	}
+
+	var refs []placeholder
+	add := func(loc *ast.Location, name *ast.Term) {
+		if s, ok := name.Value.(ast.String); ok && loc != nil {
+			refs = append(refs, placeholder{name: string(s), start: loc.Offset, end: loc.Offset + len(loc.Text)})
+		}
+	}
+	// call handles object.get(input.expectations, key, default), where key
+	// is a name or a path whose first element is one.
+	call := func(loc *ast.Location, terms []*ast.Term) {
+		if len(terms) != 4 || terms[0].Value.Compare(ast.ObjectGet.Ref()) != 0 || !isExpectations(terms[1]) {
+			return
+		}
+		if path, ok := terms[2].Value.(*ast.Array); ok && path.Len() > 0 {
+			add(loc, path.Elem(0))
+		} else {
+			add(loc, terms[2])
+		}
+	}
+
+	ast.WalkTerms(module, func(t *ast.Term) bool {
+		switch v := t.Value.(type) {
+		case ast.Ref:
+			switch {
+			case len(v) > 2 && isExpectations(ast.RefTerm(v[:2]...)):
+				add(t.Location, v[2])
+			case len(v) > 1 && isExpectations(v[0]):
+				add(t.Location, v[1])
+			}
+		case ast.Call:
+			call(t.Location, v)
+		}
+		return false
+	})
+	ast.WalkExprs(module, func(expr *ast.Expr) bool {
+		if terms, ok := expr.Terms.([]*ast.Term); ok {
+			call(expr.Location, terms)
+		}
+		return false
+	})
+
+	slices.SortStableFunc(refs, func(a, b placeholder) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	return refs
+}
+
+// expectationAliases returns the variables and rules bound to
+// input.expectations, directly or through another alias.
+func expectationAliases(module *ast.Module) map[ast.Var]bool {
+	aliases := make(map[ast.Var]bool)
+	for changed := true; changed; {
+		changed = false
+		bind := func(name, value *ast.Term) {
+			if v, ok := name.Value.(ast.Var); ok && !aliases[v] && isExpectationsDoc(value, aliases) {
+				aliases[v] = true
+				changed = true
+			}
+		}
+		for _, rule := range module.Rules {
+			if rule.Head.Value != nil && len(rule.Head.Reference) == 1 {
+				bind(rule.Head.Reference[0], rule.Head.Value)
+			}
+		}
+		ast.WalkExprs(module, func(expr *ast.Expr) bool {
+			if expr.IsAssignment() || expr.IsEquality() {
+				operands := expr.Operands()
+				bind(operands[0], operands[1])
+				bind(operands[1], operands[0])
+			}
+			return false
+		})
+	}
+	return aliases
+}
+
+// isExpectationsDoc reports whether t is input.expectations or one of its
+// aliases.
+func isExpectationsDoc(t *ast.Term, aliases map[ast.Var]bool) bool {
+	switch v := t.Value.(type) {
+	case ast.Ref:
+		return len(v) == 2 && v[0].Equal(ast.InputRootDocument) && v[1].Equal(ast.StringTerm("expectations"))
+	case ast.Var:
+		return aliases[v]
+	}
+	return false
+}
+
+// variableUses returns the names of the chapter variables the guide refers
+// to, either as text placeholders or as expectations in its validations.
+func (g Guide) variableUses() map[string]bool {
+	used := make(map[string]bool)
+	textFields(&g, func(_ []any, text *string) {
+		for _, p := range scanPlaceholders(*text) {
+			used[p.name] = true
+		}
+	})
+	for _, step := range g.Steps {
+		for _, ref := range expectationRefs(step.Validation) {
+			used[ref.name] = true
+		}
+	}
+	return used
+}
+
+// validateGuideVariables checks that every placeholder in the guide refers
+// to a variable the chapter declares. Such a typo would otherwise be shown
+// to users verbatim. Validation policies are held to the same rule, since
+// the backend only supplies expectations for declared variables.
+func (c Chapter) validateGuideVariables(g Guide, r *ValidationReport) {
+	declared := make(map[string]bool, len(c.Variables))
+	for _, v := range c.Variables {
+		declared[v.Name] = true
+	}
+
+	textFields(&g, func(field []any, text *string) {
+		for _, p := range scanPlaceholders(*text) {
+			if !declared[p.name] {
+				r.errorf(CodeVariableUndeclared, append(field, textOffset(p.start)), "guide %s: %s uses ${%s}, which is not declared by chapter %s", g.Slug, formatField(field), p.name, c.Slug)
+			}
+		}
+	})
+
+	for i, step := range g.Steps {
+		for _, ref := range expectationRefs(step.Validation) {
+			if !declared[ref.name] {
+				r.errorf(CodeExpectationUndeclared, []any{"steps", i, "validation", textOffset(ref.start)}, "guide %s: step %d validation reads input.expectations.%s, which is not declared by chapter %s", g.Slug, step.Order, ref.name, c.Slug)
+			}
+		}
+	}
+}
+
+// validateVariableUsage warns about declared variables that none of the
+// chapter's guides use.
+func (c Chapter) validateVariableUsage(r *ValidationReport) {
+	used := make(map[string]bool)
+	for _, g := range c.Guides {
+		for name := range g.variableUses() {
+			used[name] = true
+		}
+	}
+
+	for i, v := range c.Variables {
+		if !used[v.Name] {
+			r.addf(SeverityWarning, CodeVariableUnused, []any{"variables", i, "name"}, "chapter %s: variable %q is not used by any guide", c.Slug, v.Name)
+		}
+	}
+}
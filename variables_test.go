@@ -0,0 +1,201 @@
+package userguides
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const variablesChapterYAML = `name: "Test Chapter"
+description: "test"
+ordering: 1
+variables:
+  - name: "stack_name"
+    description: "Name of the stack"
+    resourceType: "stack"
+  - name: "unused_name"
+    description: "Declared but never used"
+    resourceType: "policy"
+`
+
+const variablesGuideYAML = `slug: guide-one
+ordering: 1
+metadata:
+  title: "Guide"
+` + validGuideMetadataYAML + `steps:
+  - order: 1
+    title: "Step"
+    instruction: "Create ${stack_name}, literally $${stack_nmae}."
+    hint: "Check ${stack_nmae}."
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: []
+`
+
+func TestVariables_UndeclaredIsError(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: []byte(variablesChapterYAML)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(variablesGuideYAML)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got: %v", problems)
+	}
+
+	undeclared := problems[0]
+	if undeclared.Code != CodeVariableUndeclared || undeclared.Severity != SeverityError {
+		t.Errorf("expected %s error, got %s %s", CodeVariableUndeclared, undeclared.Code, undeclared.Severity)
+	}
+	if undeclared.Path != "guides/mygroup/mychapter/guide-one.yaml" || undeclared.Line != 14 || undeclared.Column != 18 {
+		t.Errorf("expected the problem at guide-one.yaml:14:18, got %s:%d:%d", undeclared.Path, undeclared.Line, undeclared.Column)
+	}
+
+	unused := problems[1]
+	if unused.Code != CodeVariableUnused || unused.Severity != SeverityWarning {
+		t.Errorf("expected %s warning, got %s %s", CodeVariableUnused, unused.Code, unused.Severity)
+	}
+	if unused.Path != "guides/mygroup/mychapter/chapter.yaml" || unused.Line != 8 {
+		t.Errorf("expected the warning at chapter.yaml:8, got %s:%d", unused.Path, unused.Line)
+	}
+}
+
+func TestVariables_UnusedIsWarning(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: []byte(variablesChapterYAML)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: validGuideYAML("guide-one", 1)},
+	}
+
+	lib, err := Load(f)
+	if err != nil {
+		t.Fatalf("expected warnings not to fail the load, got: %v", err)
+	}
+
+	warnings := lib.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected both variables to be reported as unused, got: %v", warnings)
+	}
+	for _, w := range warnings {
+		if w.Code != CodeVariableUnused {
+			t.Errorf("expected %s, got %s", CodeVariableUnused, w.Code)
+		}
+	}
+}
+
+func TestVariables_StepTitle(t *testing.T) {
+	guide := strings.Replace(variablesGuideYAML, `title: "Step"`, `title: "Create ${stak_name}"`, 1)
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: []byte(variablesChapterYAML)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(guide)},
+	}
+
+	problems := loadProblems(t, f)
+	if len(problems) == 0 || problems[0].Code != CodeVariableUndeclared || !strings.Contains(problems[0].Message, "steps[0].title uses ${stak_name}") {
+		t.Errorf("expected the placeholder in the step title to be reported, got: %v", problems)
+	}
+}
+
+func TestVariables_EmbeddedLibraryHasNoWarnings(t *testing.T) {
+	lib, err := Guides()
+	if err != nil {
+		t.Fatalf("Guides() returned error: %v", err)
+	}
+
+	for _, w := range lib.Warnings() {
+		t.Errorf("unexpected warning: %v", w)
+	}
+}
+
+const expectationsGuideYAML = `slug: guide-one
+ordering: 1
+metadata:
+  title: "Guide"
+` + validGuideMetadataYAML + `steps:
+  - order: 1
+    title: "Step"
+    instruction: "Create ${stack_name}."
+    validationHint: "Create the policy first."
+    validation: |
+      package spacelift
+
+      valid if {
+        some policy in input.policies
+        policy.name == input.expectations["unused_name"]
+        input.expectations.polcy_name != ""
+      }
+completion:
+  successMessage: "Done"
+  recommendedGuideIds: []
+`
+
+func TestVariables_Expectations(t *testing.T) {
+	f := fstest.MapFS{
+		"guides/mygroup/group.yaml":               {Data: validGroupYAML()},
+		"guides/mygroup/mychapter/chapter.yaml":   {Data: []byte(variablesChapterYAML)},
+		"guides/mygroup/mychapter/guide-one.yaml": {Data: []byte(expectationsGuideYAML)},
+	}
+
+	// unused_name is read by the validation, so only the misspelt
+	// expectation is reported.
+	problems := loadProblems(t, f)
+	if len(problems) != 1 {
+		t.Fatalf("expected 1 problem, got: %v", problems)
+	}
+
+	p := problems[0]
+	if p.Code != CodeExpectationUndeclared || p.Line != 21 {
+		t.Errorf("expected %s on line 21, got %s on line %d", CodeExpectationUndeclared, p.Code, p.Line)
+	}
+	if !strings.Contains(p.Message, "input.expectations.polcy_name") {
+		t.Errorf("expected the message to name the expectation, got: %s", p.Message)
+	}
+}
+
+func TestExpectationRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"dot and bracket", `valid if {
+  input.expectations.main_stack_name != input.expectations[ "other_name" ]
+  input["expectations"]["third_name"] != ""
+}`, "main_stack_name,other_name,third_name"},
+		{"local alias", `valid if {
+  e := input.expectations
+  f := e
+  e.main_stack_name == f["other_name"]
+}`, "main_stack_name,other_name"},
+		{"rule alias", `exp := input.expectations
+
+valid if exp.main_stack_name != ""`, "main_stack_name"},
+		{"object.get", `valid if {
+  object.get(input.expectations, "main_stack_name", "") != ""
+  object.get(input.expectations, ["other_name"], "") != ""
+}`, "main_stack_name,other_name"},
+		{"comments and strings", `# input.expectations.in_comment
+valid if {
+  msg := "input.expectations.in_string"
+  msg != ""
+}`, ""},
+		{"computed name", `valid if {
+  some name
+  input.expectations[name] != ""
+}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, ref := range expectationRefs("package spacelift\n\n" + tt.body) {
+				names = append(names, ref.name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("expectationRefs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}